@@ -0,0 +1,90 @@
+package filtersquirrel
+
+import (
+	"fmt"
+	sq "github.com/Masterminds/squirrel"
+	"reflect"
+	"regexp"
+)
+
+// arrayParameter is a list bound as a single parameter cast to an array of its element type, see
+// WithTypedArrayParameters.
+type arrayParameter struct {
+	list        any
+	elementType string
+}
+
+// toSql renders the comparison of the column with the array parameter, e.g. tags @> ?::text[].
+func (p *arrayParameter) toSql(fieldName string, operator string) (string, []any, error) {
+	return fmt.Sprintf("%s %s %s::%s[]", fieldName, operator, sq.Placeholders(1), p.elementType), []any{p.list}, nil
+}
+
+// arrayValue returns the value of an array condition as arrayParameter if typed array parameters are enabled and
+// the element type is known. Otherwise, the value is returned as it is and its elements are bound one by one.
+func (ctx *ApplyContext) arrayValue(field string, value any) (any, error) {
+	if !ctx.options.TypedArrayParameters || value == nil || !ctx.Dialect().SupportsArrayParameters() {
+		return value, nil
+	}
+	list := listValue(value)
+	valVal := reflect.ValueOf(list)
+	if valVal.Len() == 0 {
+		return value, nil
+	}
+	if values, ok := list.([]any); ok {
+		list = typedList(values)
+	}
+	elementType, err := ctx.arrayElementType(field, reflect.TypeOf(list).Elem())
+	if err != nil || elementType == "" {
+		return value, err
+	}
+	if ctx.options.TypedArrayParameterFunc != nil {
+		list = ctx.options.TypedArrayParameterFunc(list)
+	}
+	return &arrayParameter{list: list, elementType: elementType}, nil
+}
+
+// sqlTypePattern restricts the element types of WithArrayElementType, as they are rendered into the SQL.
+var sqlTypePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_ ]*(\(\d+(, ?\d+)?\))?$`)
+
+// arrayElementType returns the SQL type of the array elements of the domain field, which is configured with
+// WithArrayElementType or derived by sqlTypeOf. It returns an empty string if the type is unknown.
+func (ctx *ApplyContext) arrayElementType(field string, t reflect.Type) (string, error) {
+	sqlType, ok := ctx.options.ArrayElementTypes[field]
+	if !ok {
+		sqlType = ctx.sqlTypeOf(field, t)
+	}
+	if sqlType != "" && !sqlTypePattern.MatchString(sqlType) {
+		return "", fmt.Errorf("invalid array element type %q for field %s", sqlType, field)
+	}
+	return sqlType, nil
+}
+
+// sqlTypeOf returns the PostgreSQL type of the values of the domain field implied by its FieldType or derived from
+// their Go type. It returns an empty string if the type is unknown.
+func (ctx *ApplyContext) sqlTypeOf(field string, t reflect.Type) string {
+	if fieldType, ok := fieldOption(ctx.options, ctx.options.FieldTypes, field); ok && fieldType.SQLType != "" {
+		return fieldType.SQLType
+	}
+	if t == timeType {
+		return "timestamptz"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "text"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int8, reflect.Int16, reflect.Uint8:
+		return "smallint"
+	case reflect.Int32, reflect.Uint16:
+		return "integer"
+	case reflect.Int, reflect.Int64, reflect.Uint32:
+		return "bigint"
+	case reflect.Uint, reflect.Uint64:
+		return "numeric"
+	case reflect.Float32:
+		return "real"
+	case reflect.Float64:
+		return "double precision"
+	}
+	return ""
+}
@@ -0,0 +1,192 @@
+package filtersquirrel
+
+import (
+	"encoding/json"
+	"fmt"
+	sq "github.com/Masterminds/squirrel"
+	"reflect"
+	"strings"
+)
+
+// ArrayOperator is the comparison of an array condition on an array which is not stored in a native array column.
+type ArrayOperator int
+
+const (
+	// ArrayContainsOperator matches if all values are elements of the array.
+	ArrayContainsOperator ArrayOperator = iota
+	// ArrayIsContainedOperator matches if all elements of the array are values.
+	ArrayIsContainedOperator
+	// ArrayOverlapsOperator matches if any value is an element of the array.
+	ArrayOverlapsOperator
+)
+
+// ArrayTable stores the elements of an array field in the rows of a child table.
+//
+//	ArrayTable{Table: "post_tags t", On: "t.post_id = p.id", Column: "t.tag"}
+type ArrayTable struct {
+	// Table is the child table including an alias, e.g. "post_tags t".
+	Table string
+	// On correlates the rows of the child table with the filtered row, e.g. "t.post_id = p.id".
+	On string
+	// Column is the column of the elements, e.g. "t.tag".
+	Column string
+}
+
+// arrayElements is a table with a row per element of an array, whose conditions are rendered as subqueries.
+type arrayElements struct {
+	// from is the table of the elements, e.g. json_each(tags).
+	from string
+	// where correlates the elements with the filtered row. It is empty for table functions of a column.
+	where string
+	// element is the column of the elements.
+	element string
+	// placeholder is the expression of a value compared with the elements, e.g. ?::jsonb.
+	placeholder string
+	// column is the array column, whose NULL values are unknown like NULL arrays. It is empty for tables.
+	column string
+}
+
+// predicate renders the comparison of the elements with the values, where NULL values never match an element.
+func (e arrayElements) predicate(operator ArrayOperator, values []any) sq.Sqlizer {
+	var nonNull []any
+	for _, v := range values {
+		if v != nil {
+			nonNull = append(nonNull, v)
+		}
+	}
+	if operator == ArrayContainsOperator && len(nonNull) < len(values) {
+		return sq.Expr(sqlFalse)
+	}
+	values = uniqueValues(nonNull)
+	placeholders := strings.TrimSuffix(strings.Repeat(e.placeholder+",", len(values)), ",")
+	where := func(condition string) string {
+		if e.where == "" {
+			return condition
+		}
+		return e.where + " AND " + condition
+	}
+	switch {
+	case operator == ArrayIsContainedOperator:
+		condition := sqlTrue
+		if len(values) > 0 {
+			condition = fmt.Sprintf("(%s IS NULL OR %s NOT IN (%s))", e.element, e.element, placeholders)
+		}
+		return e.notNull(sq.Expr(fmt.Sprintf("NOT EXISTS (SELECT 1 FROM %s WHERE %s)", e.from, where(condition)), values...))
+	case len(values) == 0:
+		return sq.Expr(sqlFalse)
+	case operator == ArrayOverlapsOperator:
+		condition := fmt.Sprintf("%s IN (%s)", e.element, placeholders)
+		return sq.Expr(fmt.Sprintf("EXISTS (SELECT 1 FROM %s WHERE %s)", e.from, where(condition)), values...)
+	case len(values) == 1:
+		condition := fmt.Sprintf("%s = %s", e.element, placeholders)
+		return sq.Expr(fmt.Sprintf("EXISTS (SELECT 1 FROM %s WHERE %s)", e.from, where(condition)), values...)
+	}
+	condition := fmt.Sprintf("%s IN (%s)", e.element, placeholders)
+	return sq.Expr(fmt.Sprintf("(SELECT COUNT(DISTINCT %s) FROM %s WHERE %s) = %d", e.element, e.from, where(condition), len(values)), values...)
+}
+
+// notNull makes the condition unknown for NULL array columns, which table functions treat like empty arrays.
+func (e arrayElements) notNull(condition sq.Sqlizer) sq.Sqlizer {
+	if e.column == "" {
+		return condition
+	}
+	return sq.And{sq.NotEq{e.column: nil}, condition}
+}
+
+// uniqueValues returns the values without duplicates.
+func uniqueValues(values []any) []any {
+	var unique []any
+	for _, v := range values {
+		duplicate := false
+		for _, u := range unique {
+			if reflect.DeepEqual(u, v) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
+
+// jsonArray returns the JSON array of the values which are not NULL and whether any value is NULL.
+func jsonArray(values []any) (string, bool, error) {
+	nonNull := make([]any, 0, len(values))
+	for _, v := range values {
+		if v != nil {
+			nonNull = append(nonNull, v)
+		}
+	}
+	document, err := json.Marshal(nonNull)
+	return string(document), len(nonNull) < len(values), err
+}
+
+// jsonValues converts the values to JSON documents to compare them with JSON elements.
+func jsonValues(values []any) ([]any, error) {
+	documents := make([]any, len(values))
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		document, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		documents[i] = string(document)
+	}
+	return documents, nil
+}
+
+// jsonTexts converts the values to the text of JSON scalars, i.e. strings as they are and other values as JSON.
+func jsonTexts(values []any) ([]any, error) {
+	texts, err := jsonValues(values)
+	if err != nil {
+		return nil, err
+	}
+	for i, v := range values {
+		if s, ok := v.(string); ok {
+			texts[i] = s
+		}
+	}
+	return texts, nil
+}
+
+// isEmulatedArray reports whether the array of the domain field is stored in a JSON column or a child table.
+func (ctx *ApplyContext) isEmulatedArray(field string) bool {
+	_, ok := ctx.options.ArrayTables[field]
+	return ok || ctx.options.JSONArrayFields[field]
+}
+
+// emulatedArray renders an array condition on an array stored in a JSON column or a child table, see
+// WithJSONArray and WithArrayTable. Nil values and empty lists are false like for native arrays.
+func (ctx *ApplyContext) emulatedArray(field string, operator ArrayOperator, value any) (sq.Sqlizer, error) {
+	if err := ctx.checkArrayValues(value); err != nil {
+		return nil, err
+	}
+	value, err := ctx.coerceValue(field, value)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return sq.Expr(sqlFalse), nil
+	}
+	list := reflect.ValueOf(listValue(value))
+	if list.Len() == 0 {
+		return sq.Expr(sqlFalse), nil
+	}
+	values := make([]any, list.Len())
+	for i := range values {
+		values[i] = list.Index(i).Interface()
+	}
+	if table, ok := ctx.options.ArrayTables[field]; ok {
+		elements := arrayElements{from: table.Table, where: table.On, element: table.Column, placeholder: "?"}
+		return elements.predicate(operator, values), nil
+	}
+	column, err := ctx.MapField(field)
+	if err != nil {
+		return nil, err
+	}
+	return ctx.Dialect().JSONArray(column, operator, values)
+}
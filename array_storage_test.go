@@ -0,0 +1,169 @@
+package filtersquirrel
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/require"
+	"github.com/xafelium/filter"
+	"testing"
+)
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestApplyFilterWithEmulatedArrays(t *testing.T) {
+	tagsTable := WithArrayTable("tags", ArrayTable{Table: "post_tags t", On: "t.post_id = p.id", Column: "t.tag"})
+	tests := []struct {
+		name         string
+		dialect      Dialect
+		opts         []Option
+		filter       filter.Condition
+		expectedSql  string
+		expectedArgs []any
+		errContains  string
+	}{
+		{
+			name:         "table array contains",
+			dialect:      MySQL,
+			opts:         []Option{tagsTable},
+			filter:       filter.Where(filter.ArrayContains("tags", "go")),
+			expectedSql:  "SELECT * FROM posts p WHERE EXISTS (SELECT 1 FROM post_tags t WHERE t.post_id = p.id AND t.tag = ?)",
+			expectedArgs: []any{"go"},
+		},
+		{
+			name:         "table array contains array",
+			dialect:      Postgres,
+			opts:         []Option{tagsTable},
+			filter:       filter.Where(filter.ArrayContainsArray("tags", []string{"go", "sql", "go"})),
+			expectedSql:  "SELECT * FROM posts p WHERE (SELECT COUNT(DISTINCT t.tag) FROM post_tags t WHERE t.post_id = p.id AND t.tag IN ($1,$2)) = 2",
+			expectedArgs: []any{"go", "sql"},
+		},
+		{
+			name:         "table array is contained",
+			dialect:      SQLite,
+			opts:         []Option{tagsTable},
+			filter:       filter.Where(filter.ArrayIsContained("tags", []any{"go", nil})),
+			expectedSql:  "SELECT * FROM posts p WHERE NOT EXISTS (SELECT 1 FROM post_tags t WHERE t.post_id = p.id AND (t.tag IS NULL OR t.tag NOT IN (?)))",
+			expectedArgs: []any{"go"},
+		},
+		{
+			name:         "table array overlaps",
+			dialect:      SQLServer,
+			opts:         []Option{tagsTable},
+			filter:       filter.Where(filter.ArraysOverlap("tags", []string{"go", "sql"})),
+			expectedSql:  "SELECT * FROM posts p WHERE EXISTS (SELECT 1 FROM post_tags t WHERE t.post_id = p.id AND t.tag IN (?,?))",
+			expectedArgs: []any{"go", "sql"},
+		},
+		{
+			name:        "table array contains array with NULL",
+			dialect:     MySQL,
+			opts:        []Option{tagsTable},
+			filter:      filter.Where(filter.ArrayContainsArray("tags", []any{"go", nil})),
+			expectedSql: "SELECT * FROM posts p WHERE (1=0)",
+		},
+		{
+			name:        "empty list",
+			dialect:     MySQL,
+			opts:        []Option{tagsTable},
+			filter:      filter.Where(filter.Overlaps("tags", []string{})),
+			expectedSql: "SELECT * FROM posts p WHERE (1=0)",
+		},
+		{
+			name:         "coerced values",
+			dialect:      MySQL,
+			opts:         []Option{WithArrayTable("ids", ArrayTable{Table: "post_ids i", On: "i.post_id = p.id", Column: "i.id"}), WithFieldType(IntType, "ids")},
+			filter:       filter.Where(filter.Overlaps("ids", []string{"1", "2"})),
+			expectedSql:  "SELECT * FROM posts p WHERE EXISTS (SELECT 1 FROM post_ids i WHERE i.post_id = p.id AND i.id IN (?,?))",
+			expectedArgs: []any{int64(1), int64(2)},
+		},
+		{
+			name:        "array contains list",
+			dialect:     MySQL,
+			opts:        []Option{tagsTable},
+			filter:      filter.Where(filter.ArrayContains("tags", []string{"go"})),
+			errContains: "value cannot be an array or slice",
+		},
+		{
+			name:         "mysql json array contains",
+			dialect:      MySQL,
+			opts:         []Option{WithJSONArray("tags")},
+			filter:       filter.Where(filter.ArrayContainsArray("tags", []string{"go", "sql"})),
+			expectedSql:  "SELECT * FROM posts p WHERE JSON_CONTAINS(tags, ?)",
+			expectedArgs: []any{`["go","sql"]`},
+		},
+		{
+			name:         "mysql json array is contained",
+			dialect:      MySQL,
+			opts:         []Option{WithJSONArray("ids")},
+			filter:       filter.Where(filter.ArrayIsContained("ids", []int{1, 2})),
+			expectedSql:  "SELECT * FROM posts p WHERE JSON_CONTAINS(?, ids)",
+			expectedArgs: []any{`[1,2]`},
+		},
+		{
+			name:         "mysql json array overlaps",
+			dialect:      MySQL,
+			opts:         []Option{WithJSONArray("tags")},
+			filter:       filter.Where(filter.Overlaps("tags", []any{"go", nil})),
+			expectedSql:  "SELECT * FROM posts p WHERE JSON_OVERLAPS(tags, ?)",
+			expectedArgs: []any{`["go"]`},
+		},
+		{
+			name:         "sqlite json array contains",
+			dialect:      SQLite,
+			opts:         []Option{WithJSONArray("tags")},
+			filter:       filter.Where(filter.ArrayContains("tags", "go")),
+			expectedSql:  "SELECT * FROM posts p WHERE EXISTS (SELECT 1 FROM json_each(tags) WHERE value = ?)",
+			expectedArgs: []any{"go"},
+		},
+		{
+			name:         "sqlite json array is contained",
+			dialect:      SQLite,
+			opts:         []Option{WithJSONArray("tags")},
+			filter:       filter.Where(filter.ArrayIsContained("tags", []string{"go", "sql"})),
+			expectedSql:  "SELECT * FROM posts p WHERE (tags IS NOT NULL AND NOT EXISTS (SELECT 1 FROM json_each(tags) WHERE (value IS NULL OR value NOT IN (?,?))))",
+			expectedArgs: []any{"go", "sql"},
+		},
+		{
+			name:         "sqlserver json array overlaps",
+			dialect:      SQLServer,
+			opts:         []Option{WithJSONArray("flags")},
+			filter:       filter.Where(filter.Overlaps("flags", []any{true, 1.5, "x"})),
+			expectedSql:  "SELECT * FROM posts p WHERE EXISTS (SELECT 1 FROM OPENJSON(flags) WHERE value IN (?,?,?))",
+			expectedArgs: []any{"true", "1.5", "x"},
+		},
+		{
+			name:         "postgres json array contains",
+			dialect:      Postgres,
+			opts:         []Option{WithJSONArray("tags")},
+			filter:       filter.Where(filter.ArrayContainsArray("tags", []string{"go"})),
+			expectedSql:  "SELECT * FROM posts p WHERE tags @> $1::jsonb",
+			expectedArgs: []any{`["go"]`},
+		},
+		{
+			name:         "postgres json array overlaps",
+			dialect:      Postgres,
+			opts:         []Option{WithJSONArray("ids")},
+			filter:       filter.Where(filter.Overlaps("ids", []int{1, 2})),
+			expectedSql:  "SELECT * FROM posts p WHERE EXISTS (SELECT 1 FROM jsonb_array_elements(ids) AS elements(value) WHERE value IN ($1::jsonb,$2::jsonb))",
+			expectedArgs: []any{"1", "2"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			builder := sq.Select("*").From("posts p")
+			if test.dialect == Postgres {
+				builder = builder.PlaceholderFormat(sq.Dollar)
+			}
+			opts := append([]Option{WithDialect(test.dialect)}, test.opts...)
+			builder, _, err := ApplyFilter(builder, test.filter, opts...)
+
+			if test.errContains != "" {
+				require.ErrorContains(t, err, test.errContains)
+				return
+			}
+			require.NoError(t, err)
+			sql, args, err := builder.ToSql()
+			require.NoError(t, err)
+			require.Equal(t, test.expectedSql, sql)
+			require.Equal(t, test.expectedArgs, args)
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package filtersquirrel
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/require"
+	"github.com/xafelium/filter"
+	"testing"
+	"time"
+)
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestApplyFilterWithTypedArrayParameters(t *testing.T) {
+	date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		opts         []Option
+		filter       filter.Condition
+		expectedSql  string
+		expectedArgs []any
+		errContains  string
+	}{
+		{
+			name:         "array contains array",
+			filter:       filter.Where(filter.ArrayContainsArray("tags", []string{"a", "b"})),
+			expectedSql:  "SELECT * FROM posts WHERE tags @> $1::text[]",
+			expectedArgs: []any{[]string{"a", "b"}},
+		},
+		{
+			name:         "array is contained",
+			filter:       filter.Where(filter.ArrayIsContained("ids", []int32{1, 2, 3})),
+			expectedSql:  "SELECT * FROM posts WHERE ids <@ $1::integer[]",
+			expectedArgs: []any{[]int32{1, 2, 3}},
+		},
+		{
+			name:         "overlaps",
+			filter:       filter.Where(filter.Overlaps("scores", []float64{1.5})),
+			expectedSql:  "SELECT * FROM posts WHERE scores && $1::double precision[]",
+			expectedArgs: []any{[]float64{1.5}},
+		},
+		{
+			name:         "arrays overlap with scalar",
+			filter:       filter.Where(filter.ArraysOverlap("dates", date)),
+			expectedSql:  "SELECT * FROM posts WHERE dates && $1::timestamptz[]",
+			expectedArgs: []any{[]time.Time{date}},
+		},
+		{
+			name:         "values of the same type",
+			filter:       filter.Where(filter.Overlaps("flags", []any{true, false})),
+			expectedSql:  "SELECT * FROM posts WHERE flags && $1::boolean[]",
+			expectedArgs: []any{[]bool{true, false}},
+		},
+		{
+			name:         "values of different types",
+			filter:       filter.Where(filter.Overlaps("tags", []any{"a", 1})),
+			expectedSql:  "SELECT * FROM posts WHERE tags && ARRAY[$1,$2]",
+			expectedArgs: []any{"a", 1},
+		},
+		{
+			name:        "empty list",
+			filter:      filter.Where(filter.Overlaps("tags", []string{})),
+			expectedSql: "SELECT * FROM posts WHERE (1=0)",
+		},
+		{
+			name:         "element type of field type",
+			opts:         []Option{WithFieldType(UUIDType, "owners")},
+			filter:       filter.Where(filter.ArrayContainsArray("owners", []string{"A0EEBC99-9C0B-4EF8-BB6D-6BB9BD380A11"})),
+			expectedSql:  "SELECT * FROM posts WHERE owners @> $1::uuid[]",
+			expectedArgs: []any{[]string{"a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"}},
+		},
+		{
+			name:         "element type of coerced values",
+			opts:         []Option{WithFieldType(IntType, "ids")},
+			filter:       filter.Where(filter.Overlaps("ids", []string{"1", "2"})),
+			expectedSql:  "SELECT * FROM posts WHERE ids && $1::bigint[]",
+			expectedArgs: []any{[]int64{1, 2}},
+		},
+		{
+			name:         "element type of custom field type",
+			opts:         []Option{WithFieldType(FieldType{Coerce: coerceString, SQLType: "citext"}, "tags")},
+			filter:       filter.Where(filter.Overlaps("tags", []string{"a"})),
+			expectedSql:  "SELECT * FROM posts WHERE tags && $1::citext[]",
+			expectedArgs: []any{[]string{"a"}},
+		},
+		{
+			name:        "invalid element type of custom field type",
+			opts:        []Option{WithFieldType(FieldType{Coerce: coerceString, SQLType: "text[]--"}, "tags")},
+			filter:      filter.Where(filter.Overlaps("tags", []string{"a"})),
+			errContains: `invalid array element type "text[]--" for field tags`,
+		},
+		{
+			name:         "configured element type",
+			opts:         []Option{WithArrayElementType("varchar(20)", "tags")},
+			filter:       filter.Where(filter.Overlaps("tags", []string{"a"})),
+			expectedSql:  "SELECT * FROM posts WHERE tags && $1::varchar(20)[]",
+			expectedArgs: []any{[]string{"a"}},
+		},
+		{
+			name:        "invalid element type",
+			opts:        []Option{WithArrayElementType("text[]; DROP TABLE posts", "tags")},
+			filter:      filter.Where(filter.Overlaps("tags", []string{"a"})),
+			errContains: `invalid array element type "text[]; DROP TABLE posts" for field tags`,
+		},
+		{
+			name:         "array parameter func",
+			opts:         []Option{WithTypedArrayParameters(func(list any) any { return intArray(list.([]int)) })},
+			filter:       filter.Where(filter.Overlaps("ids", []int{1, 2})),
+			expectedSql:  "SELECT * FROM posts WHERE ids && $1::bigint[]",
+			expectedArgs: []any{intArray{1, 2}},
+		},
+		{
+			name: "array parameter funcs of in and array conditions",
+			opts: []Option{
+				WithTypedArrayParameters(func(list any) any { return intArray(list.([]int)) }),
+				WithArrayParameters(1, func(list any) any { return list.([]int)[0] }),
+			},
+			filter:       filter.Where(filter.And(filter.Overlaps("ids", []int{1, 2}), filter.In("id", []int{3, 4}))),
+			expectedSql:  "SELECT * FROM posts WHERE (ids && $1::bigint[] AND id = ANY ($2))",
+			expectedArgs: []any{intArray{1, 2}, 3},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			builder := sq.Select("*").From("posts").PlaceholderFormat(sq.Dollar)
+			opts := append([]Option{WithDialect(Postgres), WithTypedArrayParameters(nil)}, test.opts...)
+			builder, _, err := ApplyFilter(builder, test.filter, opts...)
+
+			if test.errContains != "" {
+				require.ErrorContains(t, err, test.errContains)
+				return
+			}
+			require.NoError(t, err)
+			sql, args, err := builder.ToSql()
+			require.NoError(t, err)
+			require.Equal(t, test.expectedSql, sql)
+			require.Equal(t, test.expectedArgs, args)
+		})
+	}
+}
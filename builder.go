@@ -6,17 +6,15 @@ import (
 	"github.com/xafelium/filter"
 	"reflect"
 	"strings"
+	"unicode"
 )
 
 const (
 	// Portable true/false literals.
+	sqlTrue  = "(1=1)"
 	sqlFalse = "(1=0)"
 )
 
-var (
-	conditionBuilders = make(map[string]func(c filter.Condition, mf FieldMapperFunc, tableAliases map[string]bool) (any, error))
-)
-
 func init() {
 	conditionBuilders[filter.AndConditionType] = applyAnd
 	conditionBuilders[filter.ArrayContainsConditionType] = applyArrayContains
@@ -40,37 +38,223 @@ func init() {
 	conditionBuilders[filter.OverlapsConditionType] = applyOverlaps
 	conditionBuilders[filter.RegexConditionType] = applyRegex
 	conditionBuilders[filter.WhereConditionType] = applyWhere
+
+	conditionBuilders[StartsWithConditionType] = applyStartsWith
+	conditionBuilders[EndsWithConditionType] = applyEndsWith
+	conditionBuilders[SearchConditionType] = applySearch
+	conditionBuilders[RangeContainsConditionType] = applyRangeContains
 }
 
 func ApplyFilter(b sq.SelectBuilder, condition filter.Condition, opts ...Option) (sq.SelectBuilder, []string, error) {
-	if condition == nil {
-		return b, nil, nil
+	sqlizer, ctx, err := buildFilter(condition, FromDefaultOptions(opts...))
+	if err != nil {
+		return b, nil, err
 	}
-	options := FromDefaultOptions(opts...)
-	tableAliasesMap := make(map[string]bool)
-	sqlizers, err := applyFilter(condition, options.MapperFunc, tableAliasesMap)
+	b, err = ctx.applyJoins(b)
 	if err != nil {
 		return b, nil, err
 	}
-	var tableAliases []string
-	for alias := range tableAliasesMap {
-		tableAliases = append(tableAliases, alias)
+	if sqlizer != nil {
+		return b.Where(sqlizer), ctx.tableAliasList(), nil
 	}
-	if sqlizers != nil {
-		return b.Where(sqlizers), tableAliases, nil
+	return b, ctx.tableAliasList(), nil
+}
+
+// ApplyFilterToUpdate adds the condition to the WHERE clause of an UPDATE statement.
+func ApplyFilterToUpdate(b sq.UpdateBuilder, condition filter.Condition, opts ...Option) (sq.UpdateBuilder, []string, error) {
+	sqlizer, tableAliases, err := BuildFilter(condition, opts...)
+	if err != nil {
+		return b, nil, err
+	}
+	if sqlizer != nil {
+		return b.Where(sqlizer), tableAliases, nil
 	}
 	return b, tableAliases, nil
 }
 
-func applyFilter(condition filter.Condition, mf FieldMapperFunc, tableAliases map[string]bool) (any, error) {
-	applyFunc, ok := conditionBuilders[condition.Type()]
+// ApplyFilterToDelete adds the condition to the WHERE clause of a DELETE statement.
+func ApplyFilterToDelete(b sq.DeleteBuilder, condition filter.Condition, opts ...Option) (sq.DeleteBuilder, []string, error) {
+	sqlizer, tableAliases, err := BuildFilter(condition, opts...)
+	if err != nil {
+		return b, nil, err
+	}
+	if sqlizer != nil {
+		return b.Where(sqlizer), tableAliases, nil
+	}
+	return b, tableAliases, nil
+}
+
+// BuildFilter translates the condition into a sq.Sqlizer which can be used with any squirrel builder.
+// It returns nil if the condition is empty, together with the table aliases referenced by the condition.
+func BuildFilter(condition filter.Condition, opts ...Option) (sq.Sqlizer, []string, error) {
+	sqlizer, ctx, err := buildFilter(condition, FromDefaultOptions(opts...))
+	if err != nil {
+		return nil, nil, err
+	}
+	return sqlizer, ctx.tableAliasList(), nil
+}
+
+// Translation is the result of Translate.
+type Translation struct {
+	// Sqlizer is the translated condition or nil if the condition is empty.
+	Sqlizer sq.Sqlizer
+	// TableAliases are the referenced table aliases in the order they were first referenced.
+	TableAliases []string
+	// AliasFields lists the domain fields referencing each table alias in the order they were first referenced.
+	AliasFields map[string][]string
+}
+
+// Translate translates the condition like BuildFilter and additionally reports which fields referenced which table alias.
+func Translate(condition filter.Condition, opts ...Option) (*Translation, error) {
+	sqlizer, ctx, err := buildFilter(condition, FromDefaultOptions(opts...))
+	if err != nil {
+		return nil, err
+	}
+	return &Translation{
+		Sqlizer:      sqlizer,
+		TableAliases: ctx.tableAliasList(),
+		AliasFields:  ctx.aliasFields,
+	}, nil
+}
+
+func buildFilter(condition filter.Condition, options *Options) (sq.Sqlizer, *ApplyContext, error) {
+	ctx := newApplyContext(options)
+	if condition == nil {
+		return nil, ctx, nil
+	}
+	if err := validate(condition, options); err != nil {
+		return nil, nil, err
+	}
+	if options.Optimize {
+		// The original filter is translated first to enforce the limits and map the fields of the conditions
+		// the optimizer removes, so optimizing does not change which filters are rejected.
+		if _, err := applyFilter(condition, newApplyContext(options)); err != nil {
+			return nil, nil, err
+		}
+		condition = optimize(condition, options)
+	}
+	sqlObj, err := applyFilter(condition, ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch v := sqlObj.(type) {
+	case nil:
+		return nil, ctx, nil
+	case sq.Sqlizer:
+		return v, ctx, nil
+	case []sq.Sqlizer:
+		return sq.And(v), ctx, nil
+	}
+	return nil, nil, fmt.Errorf("unexpected data type: %T", sqlObj)
+}
+
+// ApplyContext holds the state shared by the condition builders while a filter is translated.
+type ApplyContext struct {
+	options *Options
+	// tableAliases are the referenced table aliases, aliasOrder keeps the order in which they were first referenced.
+	tableAliases map[string]bool
+	aliasOrder   []string
+	// aliasFields are the domain fields referencing a table alias.
+	aliasFields map[string][]string
+	limits      limitState
+	// leafAliases are the table aliases referenced by the comparison condition currently translated.
+	leafAliases []string
+	// inlineLeaves translates conditions as parts of the comparison condition currently translated, which counts
+	// towards the limits and is wrapped in a single EXISTS subquery for to-many joins, see applyPeriod.
+	inlineLeaves bool
+	// frames track the path of the condition currently translated.
+	frames []pathFrame
+}
+
+func newApplyContext(options *Options) *ApplyContext {
+	return &ApplyContext{
+		options:      options,
+		tableAliases: make(map[string]bool),
+		aliasFields:  make(map[string][]string),
+	}
+}
+
+// MapField maps the domain field name to its column and records the referenced table alias.
+// Paths into JSON fields are extracted as text.
+func (ctx *ApplyContext) MapField(field string) (string, error) {
+	fieldName, _, err := ctx.mapFieldValue(field, nil)
+	return fieldName, err
+}
+
+// recordTableAlias records the table alias of the column referenced by the domain field.
+func (ctx *ApplyContext) recordTableAlias(column string, field string) {
+	if alias, ok := tableAlias(column); ok {
+		ctx.addTableAlias(alias, field)
+		ctx.leafAliases = append(ctx.leafAliases, alias)
+	}
+}
+
+func (ctx *ApplyContext) addTableAlias(alias string, field string) {
+	if !ctx.tableAliases[alias] {
+		ctx.tableAliases[alias] = true
+		ctx.aliasOrder = append(ctx.aliasOrder, alias)
+	}
+	for _, f := range ctx.aliasFields[alias] {
+		if f == field {
+			return
+		}
+	}
+	ctx.aliasFields[alias] = append(ctx.aliasFields[alias], field)
+}
+
+// tableAliasList returns the referenced table aliases in the order they were first referenced.
+func (ctx *ApplyContext) tableAliasList() []string {
+	if len(ctx.aliasOrder) == 0 {
+		return nil
+	}
+	return append([]string{}, ctx.aliasOrder...)
+}
+
+// Dialect returns the selected SQL dialect.
+func (ctx *ApplyContext) Dialect() Dialect {
+	return ctx.options.Dialect
+}
+
+// Apply translates a nested condition, e.g. the operand of a custom composite condition.
+func (ctx *ApplyContext) Apply(condition filter.Condition) (any, error) {
+	return applyFilter(condition, ctx)
+}
+
+// applyFilter translates the condition and wraps errors in a ConditionError with its path.
+func applyFilter(condition filter.Condition, ctx *ApplyContext) (any, error) {
+	ctx.enterPath(condition)
+	defer ctx.leavePath()
+	sqlObj, err := applyCondition(condition, ctx)
+	if err != nil {
+		return nil, ctx.conditionError(condition, err)
+	}
+	return sqlObj, nil
+}
+
+func applyCondition(condition filter.Condition, ctx *ApplyContext) (any, error) {
+	applyFunc, ok := ctx.conditionBuilder(condition.Type())
 	if !ok {
-		return nil, fmt.Errorf("unknown condition: %s", condition.Type())
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCondition, condition.Type())
+	}
+	if ctx.inlineLeaves {
+		return applyFunc(condition, ctx)
+	}
+	if err := ctx.enter(condition); err != nil {
+		return nil, err
+	}
+	defer ctx.leave(condition)
+	if !isLeafCondition(condition) {
+		return applyFunc(condition, ctx)
+	}
+	ctx.leafAliases = ctx.leafAliases[:0]
+	sqlObj, err := applyFunc(condition, ctx)
+	if err != nil {
+		return nil, err
 	}
-	return applyFunc(condition, mf, tableAliases)
+	return ctx.applyToManyJoins(sqlObj)
 }
 
-func applyWhere(condition filter.Condition, mf FieldMapperFunc, tableAliases map[string]bool) (any, error) {
+func applyWhere(condition filter.Condition, ctx *ApplyContext) (any, error) {
 	if condition == nil {
 		return nil, nil
 	}
@@ -81,10 +265,10 @@ func applyWhere(condition filter.Condition, mf FieldMapperFunc, tableAliases map
 	if c.Condition == nil {
 		return nil, nil
 	}
-	return applyFilter(c.Condition, mf, tableAliases)
+	return applyFilter(c.Condition, ctx)
 }
 
-func applyGroup(condition filter.Condition, mf FieldMapperFunc, tableAliases map[string]bool) (any, error) {
+func applyGroup(condition filter.Condition, ctx *ApplyContext) (any, error) {
 	if condition == nil {
 		return nil, fmt.Errorf("condition is nil")
 	}
@@ -95,10 +279,10 @@ func applyGroup(condition filter.Condition, mf FieldMapperFunc, tableAliases map
 	if c.Condition == nil {
 		return nil, nil
 	}
-	return applyFilter(c.Condition, mf, tableAliases)
+	return applyFilter(c.Condition, ctx)
 }
 
-func applyOr(condition filter.Condition, mf FieldMapperFunc, tableAliases map[string]bool) (any, error) {
+func applyOr(condition filter.Condition, ctx *ApplyContext) (any, error) {
 	if condition == nil {
 		return nil, fmt.Errorf("condition is nil")
 	}
@@ -110,10 +294,10 @@ func applyOr(condition filter.Condition, mf FieldMapperFunc, tableAliases map[st
 		return nil, fmt.Errorf("OR condition must have at least two conditions")
 	}
 
-	return applyOrConjunction(c.Conditions, mf, tableAliases)
+	return applyOrConjunction(c.Conditions, ctx)
 }
 
-func applyAnd(condition filter.Condition, mf FieldMapperFunc, tableAliases map[string]bool) (any, error) {
+func applyAnd(condition filter.Condition, ctx *ApplyContext) (any, error) {
 	if condition == nil {
 		return nil, fmt.Errorf("condition is nil")
 	}
@@ -124,13 +308,13 @@ func applyAnd(condition filter.Condition, mf FieldMapperFunc, tableAliases map[s
 	if len(c.Conditions) < 2 {
 		return nil, fmt.Errorf("AND condition must have at least two conditions")
 	}
-	return applyAndConjunction(c.Conditions, mf, tableAliases)
+	return applyAndConjunction(c.Conditions, ctx)
 }
 
-func applyOrConjunction(conditions []filter.Condition, mf FieldMapperFunc, tableAliases map[string]bool) (any, error) {
+func applyOrConjunction(conditions []filter.Condition, ctx *ApplyContext) (any, error) {
 	conj := sq.Or{}
 	for _, condition := range conditions {
-		sqlObj, err := applyFilter(condition, mf, tableAliases)
+		sqlObj, err := applyFilter(condition, ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -148,10 +332,10 @@ func applyOrConjunction(conditions []filter.Condition, mf FieldMapperFunc, table
 	return conj, nil
 }
 
-func applyAndConjunction(conditions []filter.Condition, mf FieldMapperFunc, tableAliases map[string]bool) (any, error) {
+func applyAndConjunction(conditions []filter.Condition, ctx *ApplyContext) (any, error) {
 	conj := sq.And{}
 	for _, condition := range conditions {
-		sqlObj, err := applyFilter(condition, mf, tableAliases)
+		sqlObj, err := applyFilter(condition, ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -169,7 +353,7 @@ func applyAndConjunction(conditions []filter.Condition, mf FieldMapperFunc, tabl
 	return conj, nil
 }
 
-func applyEquals(condition filter.Condition, mf FieldMapperFunc, tableAliases map[string]bool) (any, error) {
+func applyEquals(condition filter.Condition, ctx *ApplyContext) (any, error) {
 	if condition == nil {
 		return nil, fmt.Errorf("condition is nil")
 	}
@@ -177,15 +361,21 @@ func applyEquals(condition filter.Condition, mf FieldMapperFunc, tableAliases ma
 	if !ok {
 		return nil, fmt.Errorf("condition is no EqualsCondition")
 	}
-	fieldName, err := mf(c.Field)
+	if ctx.isJSONObject(c.Field, c.Value) {
+		return ctx.jsonContains(c.Field, c.Value)
+	}
+	fieldName, value, err := ctx.mapFieldValue(c.Field, c.Value)
 	if err != nil {
 		return nil, err
 	}
-	addTableAlias(fieldName, tableAliases)
-	return sq.Eq{fieldName: c.Value}, nil
+	column, value := ctx.compareColumn(c.Field, fieldName, value)
+	if isListType(value) {
+		return ctx.in(column, value, false)
+	}
+	return sq.Eq{column: value}, nil
 }
 
-func applyGreaterThan(condition filter.Condition, mf FieldMapperFunc, tableAliases map[string]bool) (any, error) {
+func applyGreaterThan(condition filter.Condition, ctx *ApplyContext) (any, error) {
 	if condition == nil {
 		return nil, fmt.Errorf("condition is nil")
 	}
@@ -193,15 +383,14 @@ func applyGreaterThan(condition filter.Condition, mf FieldMapperFunc, tableAlias
 	if !ok {
 		return nil, fmt.Errorf("condition is no GreaterThanCondition")
 	}
-	fieldName, err := mf(c.Field)
+	fieldName, value, err := ctx.mapFieldValue(c.Field, c.Value)
 	if err != nil {
 		return nil, err
 	}
-	addTableAlias(fieldName, tableAliases)
-	return sq.Gt{fieldName: c.Value}, nil
+	return sq.Gt{fieldName: value}, nil
 }
 
-func applyGreaterThanOrEqual(condition filter.Condition, mf FieldMapperFunc, tableAliases map[string]bool) (any, error) {
+func applyGreaterThanOrEqual(condition filter.Condition, ctx *ApplyContext) (any, error) {
 	if condition == nil {
 		return nil, fmt.Errorf("condition is nil")
 	}
@@ -209,15 +398,14 @@ func applyGreaterThanOrEqual(condition filter.Condition, mf FieldMapperFunc, tab
 	if !ok {
 		return nil, fmt.Errorf("condition is no GreaterThanOrEqualCondition")
 	}
-	fieldName, err := mf(c.Field)
+	fieldName, value, err := ctx.mapFieldValue(c.Field, c.Value)
 	if err != nil {
 		return nil, err
 	}
-	addTableAlias(fieldName, tableAliases)
-	return sq.GtOrEq{fieldName: c.Value}, nil
+	return sq.GtOrEq{fieldName: value}, nil
 }
 
-func applyLowerThan(condition filter.Condition, mf FieldMapperFunc, tableAliases map[string]bool) (any, error) {
+func applyLowerThan(condition filter.Condition, ctx *ApplyContext) (any, error) {
 	if condition == nil {
 		return nil, fmt.Errorf("condition is nil")
 	}
@@ -225,15 +413,14 @@ func applyLowerThan(condition filter.Condition, mf FieldMapperFunc, tableAliases
 	if !ok {
 		return nil, fmt.Errorf("condition is no LowerThanCondition")
 	}
-	fieldName, err := mf(c.Field)
+	fieldName, value, err := ctx.mapFieldValue(c.Field, c.Value)
 	if err != nil {
 		return nil, err
 	}
-	addTableAlias(fieldName, tableAliases)
-	return sq.Lt{fieldName: c.Value}, nil
+	return sq.Lt{fieldName: value}, nil
 }
 
-func applyLowerThanOrEqual(condition filter.Condition, mf FieldMapperFunc, tableAliases map[string]bool) (any, error) {
+func applyLowerThanOrEqual(condition filter.Condition, ctx *ApplyContext) (any, error) {
 	if condition == nil {
 		return nil, fmt.Errorf("condition is nil")
 	}
@@ -241,15 +428,14 @@ func applyLowerThanOrEqual(condition filter.Condition, mf FieldMapperFunc, table
 	if !ok {
 		return nil, fmt.Errorf("condition is no LowerThanOrEqualCondition")
 	}
-	fieldName, err := mf(c.Field)
+	fieldName, value, err := ctx.mapFieldValue(c.Field, c.Value)
 	if err != nil {
 		return nil, err
 	}
-	addTableAlias(fieldName, tableAliases)
-	return sq.LtOrEq{fieldName: c.Value}, nil
+	return sq.LtOrEq{fieldName: value}, nil
 }
 
-func applyContains(condition filter.Condition, mf FieldMapperFunc, tableAliases map[string]bool) (any, error) {
+func applyContains(condition filter.Condition, ctx *ApplyContext) (any, error) {
 	if condition == nil {
 		return nil, fmt.Errorf("condition is nil")
 	}
@@ -257,15 +443,47 @@ func applyContains(condition filter.Condition, mf FieldMapperFunc, tableAliases
 	if !ok {
 		return nil, fmt.Errorf("condition is no ContainsCondition")
 	}
-	fieldName, err := mf(c.Field)
+	fieldName, err := ctx.MapField(c.Field)
 	if err != nil {
 		return nil, err
 	}
-	addTableAlias(fieldName, tableAliases)
-	return sq.ILike{fieldName: fmt.Sprintf("%%%s%%", c.Value)}, nil
+	if language, ok := ctx.options.FullTextSearch[c.Field]; ok {
+		return ctx.search(fieldName, c.Value, language)
+	}
+	return ctx.Dialect().Like(fieldName, "%"+ctx.Dialect().EscapeLike(c.Value)+"%", ctx.likeCaseSensitive(c.Field))
 }
 
-func applyIn(condition filter.Condition, mf FieldMapperFunc, tableAliases map[string]bool) (any, error) {
+func applyStartsWith(condition filter.Condition, ctx *ApplyContext) (any, error) {
+	if condition == nil {
+		return nil, fmt.Errorf("condition is nil")
+	}
+	c, ok := condition.(*StartsWithCondition)
+	if !ok {
+		return nil, fmt.Errorf("condition is no StartsWithCondition")
+	}
+	fieldName, err := ctx.MapField(c.Field)
+	if err != nil {
+		return nil, err
+	}
+	return ctx.Dialect().Like(fieldName, ctx.Dialect().EscapeLike(c.Value)+"%", ctx.likeCaseSensitive(c.Field))
+}
+
+func applyEndsWith(condition filter.Condition, ctx *ApplyContext) (any, error) {
+	if condition == nil {
+		return nil, fmt.Errorf("condition is nil")
+	}
+	c, ok := condition.(*EndsWithCondition)
+	if !ok {
+		return nil, fmt.Errorf("condition is no EndsWithCondition")
+	}
+	fieldName, err := ctx.MapField(c.Field)
+	if err != nil {
+		return nil, err
+	}
+	return ctx.Dialect().Like(fieldName, "%"+ctx.Dialect().EscapeLike(c.Value), ctx.likeCaseSensitive(c.Field))
+}
+
+func applyIn(condition filter.Condition, ctx *ApplyContext) (any, error) {
 	if condition == nil {
 		return nil, fmt.Errorf("condition is nil")
 	}
@@ -273,15 +491,15 @@ func applyIn(condition filter.Condition, mf FieldMapperFunc, tableAliases map[st
 	if !ok {
 		return nil, fmt.Errorf("condition is no InCondition")
 	}
-	fieldName, err := mf(c.Field)
+	fieldName, value, err := ctx.mapFieldValue(c.Field, c.Value)
 	if err != nil {
 		return nil, err
 	}
-	addTableAlias(fieldName, tableAliases)
-	return sq.Eq{fieldName: c.Value}, nil
+	column, value := ctx.compareColumn(c.Field, fieldName, value)
+	return ctx.in(column, value, false)
 }
 
-func applyArrayContains(condition filter.Condition, mf FieldMapperFunc, tableAliases map[string]bool) (any, error) {
+func applyArrayContains(condition filter.Condition, ctx *ApplyContext) (any, error) {
 	if condition == nil {
 		return nil, fmt.Errorf("condition is nil")
 	}
@@ -289,12 +507,24 @@ func applyArrayContains(condition filter.Condition, mf FieldMapperFunc, tableAli
 	if !ok {
 		return nil, fmt.Errorf("condition is no ArrayContainsCondition")
 	}
-	fieldName, err := mf(c.Field)
+	if ctx.isEmulatedArray(c.Field) {
+		if c.Value == nil {
+			return nil, fmt.Errorf("value cannot be nil")
+		}
+		if isListType(c.Value) {
+			return nil, fmt.Errorf("value cannot be an array or slice")
+		}
+		return ctx.emulatedArray(c.Field, ArrayContainsOperator, c.Value)
+	}
+	fieldName, err := ctx.mapArrayField(c.Field)
+	if err != nil {
+		return nil, err
+	}
+	value, err := ctx.coerceValue(c.Field, c.Value)
 	if err != nil {
 		return nil, err
 	}
-	addTableAlias(fieldName, tableAliases)
-	return &ArrayContains{fieldName: fieldName, value: c.Value}, nil
+	return ctx.Dialect().ArrayContains(fieldName, value)
 }
 
 type ArrayContains struct {
@@ -309,7 +539,7 @@ func (a *ArrayContains) ToSql() (string, []interface{}, error) {
 	return fmt.Sprintf("%s = ANY (%s)", a.fieldName, sq.Placeholders(1)), []any{a.value}, nil
 }
 
-func applyArrayContainsArray(condition filter.Condition, mf FieldMapperFunc, tableAliases map[string]bool) (any, error) {
+func applyArrayContainsArray(condition filter.Condition, ctx *ApplyContext) (any, error) {
 	if condition == nil {
 		return nil, fmt.Errorf("condition is nil")
 	}
@@ -317,12 +547,25 @@ func applyArrayContainsArray(condition filter.Condition, mf FieldMapperFunc, tab
 	if !ok {
 		return nil, fmt.Errorf("condition is no ArrayContainsArrayCondition")
 	}
-	fieldName, err := mf(c.Field)
+	if ctx.isEmulatedArray(c.Field) {
+		return ctx.emulatedArray(c.Field, ArrayContainsOperator, c.Value)
+	}
+	fieldName, err := ctx.mapArrayField(c.Field)
 	if err != nil {
 		return nil, err
 	}
-	addTableAlias(fieldName, tableAliases)
-	return &ArrayContainsArray{fieldName: fieldName, value: c.Value}, nil
+	if err := ctx.checkArrayValues(c.Value); err != nil {
+		return nil, err
+	}
+	value, err := ctx.coerceValue(c.Field, c.Value)
+	if err != nil {
+		return nil, err
+	}
+	value, err = ctx.arrayValue(c.Field, value)
+	if err != nil {
+		return nil, err
+	}
+	return ctx.Dialect().ArrayContainsArray(fieldName, value)
 }
 
 type ArrayContainsArray struct {
@@ -331,6 +574,9 @@ type ArrayContainsArray struct {
 }
 
 func (a *ArrayContainsArray) ToSql() (sql string, args []interface{}, err error) {
+	if p, ok := a.value.(*arrayParameter); ok {
+		return p.toSql(a.fieldName, "@>")
+	}
 	if a.value == nil {
 		sql = sqlFalse
 		return
@@ -352,7 +598,7 @@ func (a *ArrayContainsArray) ToSql() (sql string, args []interface{}, err error)
 	return
 }
 
-func applyArrayIsContained(condition filter.Condition, mf FieldMapperFunc, tableAliases map[string]bool) (any, error) {
+func applyArrayIsContained(condition filter.Condition, ctx *ApplyContext) (any, error) {
 	if condition == nil {
 		return nil, fmt.Errorf("condition is nil")
 	}
@@ -360,12 +606,25 @@ func applyArrayIsContained(condition filter.Condition, mf FieldMapperFunc, table
 	if !ok {
 		return nil, fmt.Errorf("condition is no ArrayIsContainedCondition")
 	}
-	fieldName, err := mf(c.Field)
+	if ctx.isEmulatedArray(c.Field) {
+		return ctx.emulatedArray(c.Field, ArrayIsContainedOperator, c.Value)
+	}
+	fieldName, err := ctx.mapArrayField(c.Field)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.checkArrayValues(c.Value); err != nil {
+		return nil, err
+	}
+	value, err := ctx.coerceValue(c.Field, c.Value)
+	if err != nil {
+		return nil, err
+	}
+	value, err = ctx.arrayValue(c.Field, value)
 	if err != nil {
 		return nil, err
 	}
-	addTableAlias(fieldName, tableAliases)
-	return &ArrayIsContained{fieldName: fieldName, value: c.Value}, nil
+	return ctx.Dialect().ArrayIsContained(fieldName, value)
 }
 
 type ArrayIsContained struct {
@@ -374,6 +633,9 @@ type ArrayIsContained struct {
 }
 
 func (a *ArrayIsContained) ToSql() (sql string, args []interface{}, err error) {
+	if p, ok := a.value.(*arrayParameter); ok {
+		return p.toSql(a.fieldName, "<@")
+	}
 	if a.value == nil {
 		sql = sqlFalse
 		return
@@ -395,7 +657,7 @@ func (a *ArrayIsContained) ToSql() (sql string, args []interface{}, err error) {
 	return
 }
 
-func applyRegex(condition filter.Condition, mf FieldMapperFunc, tableAliases map[string]bool) (any, error) {
+func applyRegex(condition filter.Condition, ctx *ApplyContext) (any, error) {
 	if condition == nil {
 		return nil, fmt.Errorf("condition is nil")
 	}
@@ -403,15 +665,11 @@ func applyRegex(condition filter.Condition, mf FieldMapperFunc, tableAliases map
 	if !ok {
 		return nil, fmt.Errorf("condition is no RegexCondition")
 	}
-	fieldName, err := mf(c.Field)
+	fieldName, err := ctx.MapField(c.Field)
 	if err != nil {
 		return nil, err
 	}
-	addTableAlias(fieldName, tableAliases)
-	return &Regex{
-		fieldName:  fieldName,
-		expression: c.Expression,
-	}, nil
+	return ctx.Dialect().Regex(fieldName, c.Expression)
 }
 
 type Regex struct {
@@ -423,7 +681,7 @@ func (r *Regex) ToSql() (string, []interface{}, error) {
 	return fmt.Sprintf("%s ~ %s", r.fieldName, sq.Placeholders(1)), []any{r.expression}, nil
 }
 
-func applyNotRegex(condition filter.Condition, mf FieldMapperFunc, tableAliases map[string]bool) (any, error) {
+func applyNotRegex(condition filter.Condition, ctx *ApplyContext) (any, error) {
 	if condition == nil {
 		return nil, fmt.Errorf("condition is nil")
 	}
@@ -431,15 +689,11 @@ func applyNotRegex(condition filter.Condition, mf FieldMapperFunc, tableAliases
 	if !ok {
 		return nil, fmt.Errorf("condition is no NotRegexCondition")
 	}
-	fieldName, err := mf(c.Field)
+	fieldName, err := ctx.MapField(c.Field)
 	if err != nil {
 		return nil, err
 	}
-	addTableAlias(fieldName, tableAliases)
-	return &NotRegex{
-		fieldName:  fieldName,
-		expression: c.Expression,
-	}, nil
+	return ctx.Dialect().NotRegex(fieldName, c.Expression)
 }
 
 type NotRegex struct {
@@ -451,7 +705,7 @@ func (r *NotRegex) ToSql() (string, []interface{}, error) {
 	return fmt.Sprintf("%s !~ %s", r.fieldName, sq.Placeholders(1)), []any{r.expression}, nil
 }
 
-func applyIsNil(condition filter.Condition, mf FieldMapperFunc, tableAliases map[string]bool) (any, error) {
+func applyIsNil(condition filter.Condition, ctx *ApplyContext) (any, error) {
 	if condition == nil {
 		return nil, fmt.Errorf("condition is nil")
 	}
@@ -459,15 +713,14 @@ func applyIsNil(condition filter.Condition, mf FieldMapperFunc, tableAliases map
 	if !ok {
 		return nil, fmt.Errorf("condition is no IsNilCondition")
 	}
-	fieldName, err := mf(c.Field)
+	fieldName, err := ctx.MapField(c.Field)
 	if err != nil {
 		return nil, err
 	}
-	addTableAlias(fieldName, tableAliases)
 	return sq.Eq{fieldName: nil}, nil
 }
 
-func applyNot(condition filter.Condition, mf FieldMapperFunc, tableAliases map[string]bool) (any, error) {
+func applyNot(condition filter.Condition, ctx *ApplyContext) (any, error) {
 	if condition == nil {
 		return nil, fmt.Errorf("condition is nil")
 	}
@@ -476,10 +729,17 @@ func applyNot(condition filter.Condition, mf FieldMapperFunc, tableAliases map[s
 		return nil, fmt.Errorf("condition is no NotCondition")
 	}
 
-	inner, err := applyFilter(c.Condition, mf, tableAliases)
+	inner, err := applyFilter(c.Condition, ctx)
 	if err != nil {
 		return nil, err
 	}
+	if ctx.options.NullAwareNegation {
+		sqlObj, ok := inner.(sq.Sqlizer)
+		if !ok {
+			return nil, fmt.Errorf("expected sq.Sqlizer but got %T", inner)
+		}
+		return ctx.Dialect().IsNotTrue(sqlObj), nil
+	}
 	return &Not{
 		inner: inner,
 	}, nil
@@ -498,7 +758,7 @@ func (n *Not) ToSql() (string, []interface{}, error) {
 	return fmt.Sprintf("NOT (%s)", sql), args, err
 }
 
-func applyNotEquals(condition filter.Condition, mf FieldMapperFunc, tableAliases map[string]bool) (any, error) {
+func applyNotEquals(condition filter.Condition, ctx *ApplyContext) (any, error) {
 	if condition == nil {
 		return nil, fmt.Errorf("condition is nil")
 	}
@@ -506,15 +766,29 @@ func applyNotEquals(condition filter.Condition, mf FieldMapperFunc, tableAliases
 	if !ok {
 		return nil, fmt.Errorf("condition is no NotEqualsCondition")
 	}
-	fieldName, err := mf(c.Field)
+	fieldName, value, err := ctx.mapFieldValue(c.Field, c.Value)
 	if err != nil {
 		return nil, err
 	}
-	addTableAlias(fieldName, tableAliases)
-	return sq.NotEq{fieldName: c.Value}, nil
+	column, value := ctx.compareColumn(c.Field, fieldName, value)
+	switch {
+	case value == nil:
+		return sq.NotEq{column: nil}, nil
+	case isListType(value) && ctx.options.NullAwareNegation:
+		in, err := ctx.in(column, value, true)
+		if err != nil {
+			return nil, err
+		}
+		return sq.Or{in, sq.Eq{column: nil}}, nil
+	case isListType(value):
+		return ctx.in(column, value, true)
+	case !ctx.options.NullAwareNegation:
+		return sq.NotEq{column: value}, nil
+	}
+	return ctx.Dialect().IsDistinctFrom(column, value), nil
 }
 
-func applyNotNil(condition filter.Condition, mf FieldMapperFunc, tableAliases map[string]bool) (any, error) {
+func applyNotNil(condition filter.Condition, ctx *ApplyContext) (any, error) {
 	if condition == nil {
 		return nil, fmt.Errorf("condition is nil")
 	}
@@ -522,15 +796,14 @@ func applyNotNil(condition filter.Condition, mf FieldMapperFunc, tableAliases ma
 	if !ok {
 		return nil, fmt.Errorf("condition is no NotNilCondition")
 	}
-	fieldName, err := mf(c.Field)
+	fieldName, err := ctx.MapField(c.Field)
 	if err != nil {
 		return nil, err
 	}
-	addTableAlias(fieldName, tableAliases)
 	return sq.NotEq{fieldName: nil}, nil
 }
 
-func applyOverlaps(condition filter.Condition, mf FieldMapperFunc, tableAliases map[string]bool) (any, error) {
+func applyOverlaps(condition filter.Condition, ctx *ApplyContext) (any, error) {
 	if condition == nil {
 		return nil, fmt.Errorf("condition is nil")
 	}
@@ -538,12 +811,28 @@ func applyOverlaps(condition filter.Condition, mf FieldMapperFunc, tableAliases
 	if !ok {
 		return nil, fmt.Errorf("condition is no OverlapsCondition")
 	}
-	fieldName, err := mf(c.Field)
+	if r, ok := rangeValue(c.Value); ok {
+		return ctx.rangeOverlaps(c.Field, r)
+	}
+	if ctx.isEmulatedArray(c.Field) {
+		return ctx.emulatedArray(c.Field, ArrayOverlapsOperator, c.Value)
+	}
+	fieldName, err := ctx.mapArrayField(c.Field)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.checkArrayValues(c.Value); err != nil {
+		return nil, err
+	}
+	value, err := ctx.coerceValue(c.Field, c.Value)
 	if err != nil {
 		return nil, err
 	}
-	addTableAlias(fieldName, tableAliases)
-	return &Overlaps{fieldName: fieldName, value: c.Value}, nil
+	value, err = ctx.arrayValue(c.Field, value)
+	if err != nil {
+		return nil, err
+	}
+	return ctx.Dialect().Overlaps(fieldName, value)
 }
 
 type Overlaps struct {
@@ -552,6 +841,9 @@ type Overlaps struct {
 }
 
 func (o *Overlaps) ToSql() (sql string, args []interface{}, err error) {
+	if p, ok := o.value.(*arrayParameter); ok {
+		return p.toSql(o.fieldName, "&&")
+	}
 	if o.value == nil {
 		sql = sqlFalse
 		return
@@ -573,7 +865,7 @@ func (o *Overlaps) ToSql() (sql string, args []interface{}, err error) {
 	return
 }
 
-func applyArraysOverlap(condition filter.Condition, mf FieldMapperFunc, tableAliases map[string]bool) (any, error) {
+func applyArraysOverlap(condition filter.Condition, ctx *ApplyContext) (any, error) {
 	if condition == nil {
 		return nil, fmt.Errorf("condition is nil")
 	}
@@ -581,7 +873,7 @@ func applyArraysOverlap(condition filter.Condition, mf FieldMapperFunc, tableAli
 	if !ok {
 		return nil, fmt.Errorf("condition is no ArraysOverlapCondition")
 	}
-	return applyOverlaps(filter.Overlaps(c.Field, c.Value), mf, tableAliases)
+	return applyOverlaps(filter.Overlaps(c.Field, c.Value), ctx)
 }
 
 func isListType(val any) bool {
@@ -589,10 +881,48 @@ func isListType(val any) bool {
 	return valVal.Kind() == reflect.Array || valVal.Kind() == reflect.Slice
 }
 
-func addTableAlias(fieldName string, tableAliases map[string]bool) {
-	tokens := strings.Split(fieldName, ".")
-	if len(tokens) < 2 {
-		return
+// tableAlias returns the alias qualifying the column, i.e. the identifier before the first dot. The identifier may be
+// quoted, e.g. "u"."email", `u`.`email` or [u].[email]. Expressions like the JSON path attributes->>'a.b' are not
+// qualified by the identifiers or keys they contain.
+func tableAlias(fieldName string) (string, bool) {
+	end := quotedIdentifierEnd(fieldName)
+	if end < 0 {
+		end = strings.IndexFunc(fieldName, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '$'
+		})
+	}
+	if end <= 0 || fieldName[end] != '.' {
+		return "", false
+	}
+	return fieldName[:end], true
+}
+
+// quotedIdentifierEnd returns the end of the quoted identifier the field name starts with, or -1 if it does not start
+// with one. A doubled closing quote is part of the identifier.
+func quotedIdentifierEnd(fieldName string) int {
+	if fieldName == "" {
+		return -1
+	}
+	var closing byte
+	switch fieldName[0] {
+	case '"':
+		closing = '"'
+	case '`':
+		closing = '`'
+	case '[':
+		closing = ']'
+	default:
+		return -1
+	}
+	for i := 1; i < len(fieldName); i++ {
+		if fieldName[i] != closing {
+			continue
+		}
+		if i+1 < len(fieldName) && fieldName[i+1] == closing {
+			i++
+			continue
+		}
+		return i + 1
 	}
-	tableAliases[tokens[0]] = true
+	return -1
 }
@@ -15,24 +15,28 @@ func TestImplementsAllConditionTypes(t *testing.T) {
 		actual = append(actual, t)
 	}
 	sort.Strings(actual)
-	expected := filter.AllConditionTypes()
+	expected := append(filter.AllConditionTypes(), ConditionTypes()...)
 	sort.Strings(expected)
 	require.Equal(t, expected, actual)
 }
 
+type applyFilterTest struct {
+	name                 string
+	filter               filter.Condition
+	builder              sq.SelectBuilder
+	expectedSql          string
+	expectedArgs         []any
+	expectedTableAliases []string
+	errContains          string
+	mapperFunc           FieldMapperFunc
+}
+
+// applyFilterTests are shared by TestApplyFilter and the round trip test of ParseWhere.
+//
 //goland:noinspection SqlNoDataSourceInspection,SqlResolve
-func TestApplyFilter(t *testing.T) {
+func applyFilterTests() []applyFilterTest {
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
-	tests := []struct {
-		name                 string
-		filter               filter.Condition
-		builder              sq.SelectBuilder
-		expectedSql          string
-		expectedArgs         []any
-		expectedTableAliases []string
-		errContains          string
-		mapperFunc           FieldMapperFunc
-	}{
+	return []applyFilterTest{
 		{
 			name:        "nil condition",
 			filter:      nil,
@@ -252,6 +256,42 @@ func TestApplyFilter(t *testing.T) {
 			expectedSql:  "SELECT * FROM g WHERE label ILIKE $1",
 			expectedArgs: []any{"%abc%"},
 		},
+		{
+			name: "contains with wildcards",
+			filter: filter.Where(
+				filter.Contains("label", "50%_off\\"),
+			),
+			builder:      psql.Select("*").From("g"),
+			expectedSql:  "SELECT * FROM g WHERE label ILIKE $1",
+			expectedArgs: []any{"%50\\%\\_off\\\\%"},
+		},
+		{
+			name: "contains with only wildcard",
+			filter: filter.Where(
+				filter.Contains("label", "%"),
+			),
+			builder:      psql.Select("*").From("g"),
+			expectedSql:  "SELECT * FROM g WHERE label ILIKE $1",
+			expectedArgs: []any{"%\\%%"},
+		},
+		{
+			name:         "starts with",
+			filter:       filter.Where(StartsWith("label", "abc")),
+			builder:      psql.Select("*").From("g"),
+			expectedSql:  "SELECT * FROM g WHERE label ILIKE $1",
+			expectedArgs: []any{"abc%"},
+		},
+		{
+			name:                 "ends with and alias",
+			filter:               filter.Where(EndsWith("label", "_x")),
+			builder:              psql.Select("*").From("g x"),
+			expectedSql:          "SELECT * FROM g x WHERE x.label ILIKE $1",
+			expectedArgs:         []any{"%\\_x"},
+			expectedTableAliases: []string{"x"},
+			mapperFunc: func(fieldName string) (string, error) {
+				return fmt.Sprintf("x.%s", fieldName), nil
+			},
+		},
 		{
 			name: "contains with alias",
 			filter: filter.Where(
@@ -421,8 +461,10 @@ func TestApplyFilter(t *testing.T) {
 			},
 		},
 	}
+}
 
-	for _, test := range tests {
+func TestApplyFilter(t *testing.T) {
+	for _, test := range applyFilterTests() {
 		t.Run(test.name, func(t *testing.T) {
 			test := test
 			mapperFunc := test.mapperFunc
@@ -447,20 +489,128 @@ func TestApplyFilter(t *testing.T) {
 				require.NoError(t, err)
 				require.Equal(t, test.expectedSql, sql)
 				require.Equal(t, test.expectedArgs, args)
-				assertEqualElements(t, test.expectedTableAliases, tableAliases)
+				require.Equal(t, test.expectedTableAliases, tableAliases)
 			}
 		})
 	}
 }
 
-func assertEqualElements(t *testing.T, expected []string, actual []string) {
-	expectedMap := make(map[string]bool)
-	actualMap := make(map[string]bool)
-	for _, v := range expected {
-		expectedMap[v] = true
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestApplyFilterToUpdate(t *testing.T) {
+	builder := sq.Update("users u").Set("archived", true).PlaceholderFormat(sq.Dollar)
+	condition := filter.Where(filter.And(
+		filter.Equals("status", "inactive"),
+		filter.Contains("email", "example.com"),
+	))
+
+	builder, tableAliases, err := ApplyFilterToUpdate(builder, condition, WithMapperFunc(func(fieldName string) (string, error) {
+		return fmt.Sprintf("u.%s", fieldName), nil
+	}))
+
+	require.NoError(t, err)
+	sql, args, err := builder.ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "UPDATE users u SET archived = $1 WHERE (u.status = $2 AND u.email ILIKE $3)", sql)
+	require.Equal(t, []any{true, "inactive", "%example.com%"}, args)
+	require.Equal(t, []string{"u"}, tableAliases)
+}
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestApplyFilterToDelete(t *testing.T) {
+	builder := sq.Delete("sessions").PlaceholderFormat(sq.Dollar)
+
+	builder, _, err := ApplyFilterToDelete(builder, filter.Where(filter.LowerThan("expires_at", 100)))
+
+	require.NoError(t, err)
+	sql, args, err := builder.ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "DELETE FROM sessions WHERE expires_at < $1", sql)
+	require.Equal(t, []any{100}, args)
+}
+
+func TestApplyFilterToDeleteWithError(t *testing.T) {
+	_, _, err := ApplyFilterToDelete(sq.Delete("sessions"), filter.Where(filter.Or()))
+	require.ErrorContains(t, err, "OR condition must have at least two conditions")
+}
+
+func TestApplyFilterTableAliasOrder(t *testing.T) {
+	condition := filter.Where(filter.Or(
+		filter.Equals("z.a", 1),
+		filter.Equals("a.b", 2),
+		filter.Equals("m.c", 3),
+		filter.Equals("a.d", 4),
+		filter.Equals("z.e", 5),
+	))
+	for i := 0; i < 20; i++ {
+		_, tableAliases, err := ApplyFilter(sq.Select("*").From("x"), condition)
+		require.NoError(t, err)
+		require.Equal(t, []string{"z", "a", "m"}, tableAliases)
 	}
-	for _, v := range actual {
-		actualMap[v] = true
+}
+
+func TestApplyFilterWithQuotedTableAliases(t *testing.T) {
+	condition := filter.Where(filter.And(
+		filter.Equals("email", "a@b.c"),
+		filter.Equals("street", "Main St"),
+		filter.Equals("city", "Berlin"),
+		filter.Equals("country", "DE"),
+	))
+	columns := map[string]string{
+		"email":   `"u"."email"`,
+		"street":  `"a ""b"""."street"`,
+		"city":    "`c`.`city`",
+		"country": "[n].[country]",
 	}
-	require.Equal(t, expectedMap, actualMap)
+	_, tableAliases, err := ApplyFilter(sq.Select("*").From("users"), condition, WithMapperFunc(func(fieldName string) (string, error) {
+		return columns[fieldName], nil
+	}))
+	require.NoError(t, err)
+	require.Equal(t, []string{`"u"`, `"a ""b"""`, "`c`", "[n]"}, tableAliases)
+}
+
+func TestTranslate(t *testing.T) {
+	translation, err := Translate(
+		filter.Where(filter.And(
+			filter.Equals("city", "Berlin"),
+			filter.Equals("name", "John"),
+			filter.Contains("street", "Main"),
+			filter.NotEquals("city", "Hamburg"),
+			filter.IsNil("deleted_at"),
+		)),
+		WithMapperFunc(func(fieldName string) (string, error) {
+			switch fieldName {
+			case "city", "street":
+				return "a." + fieldName, nil
+			case "deleted_at":
+				return fieldName, nil
+			}
+			return "u." + fieldName, nil
+		}),
+	)
+	require.NoError(t, err)
+	require.NotNil(t, translation.Sqlizer)
+	require.Equal(t, []string{"a", "u"}, translation.TableAliases)
+	require.Equal(t, map[string][]string{
+		"a": {"city", "street"},
+		"u": {"name"},
+	}, translation.AliasFields)
+
+	translation, err = Translate(nil)
+	require.NoError(t, err)
+	require.Nil(t, translation.Sqlizer)
+	require.Empty(t, translation.TableAliases)
+}
+
+func TestBuildFilter(t *testing.T) {
+	sqlizer, tableAliases, err := BuildFilter(filter.Where(nil))
+	require.NoError(t, err)
+	require.Nil(t, sqlizer)
+	require.Nil(t, tableAliases)
+
+	sqlizer, _, err = BuildFilter(filter.Where(filter.Equals("id", 1)))
+	require.NoError(t, err)
+	sql, args, err := sqlizer.ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "id = ?", sql)
+	require.Equal(t, []any{1}, args)
 }
@@ -0,0 +1,71 @@
+package filtersquirrel
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// CaseSensitivity controls how string values of a field are compared.
+type CaseSensitivity int
+
+const (
+	// CaseDefault keeps the default behaviour: Contains, StartsWith and EndsWith ignore the case,
+	// Equals, NotEquals and In compare the values as they are.
+	CaseDefault CaseSensitivity = iota
+	// CaseSensitive compares the values case-sensitively.
+	CaseSensitive
+	// CaseInsensitive compares the values case-insensitively.
+	CaseInsensitive
+)
+
+// caseSensitivity returns the configured CaseSensitivity of the domain field.
+func (ctx *ApplyContext) caseSensitivity(field string) CaseSensitivity {
+	return ctx.options.CaseSensitivity[field]
+}
+
+// likeCaseSensitive reports whether LIKE patterns on the field are matched case-sensitively.
+func (ctx *ApplyContext) likeCaseSensitive(field string) bool {
+	return ctx.caseSensitivity(field) == CaseSensitive
+}
+
+// compareColumn returns the column expression and value to compare with respect to the configured CaseSensitivity.
+func (ctx *ApplyContext) compareColumn(field string, fieldName string, value any) (string, any) {
+	switch ctx.caseSensitivity(field) {
+	case CaseSensitive:
+		return ctx.Dialect().CaseSensitive(fieldName), value
+	case CaseInsensitive:
+		return fmt.Sprintf("LOWER(%s)", fieldName), lowerValue(value)
+	}
+	return fieldName, value
+}
+
+// lowerValue converts strings, including named string types and pointers to strings, and lists of them to lower
+// case. Other values are returned as they are.
+func lowerValue(value any) any {
+	switch v := value.(type) {
+	case string:
+		return strings.ToLower(v)
+	case []string:
+		lowered := make([]string, len(v))
+		for i, s := range v {
+			lowered[i] = strings.ToLower(s)
+		}
+		return lowered
+	}
+	valVal := reflect.ValueOf(value)
+	for valVal.Kind() == reflect.Pointer && !valVal.IsNil() {
+		valVal = valVal.Elem()
+	}
+	switch valVal.Kind() {
+	case reflect.String:
+		return strings.ToLower(valVal.String())
+	case reflect.Array, reflect.Slice:
+		lowered := make([]any, valVal.Len())
+		for i := 0; i < valVal.Len(); i++ {
+			lowered[i] = lowerValue(valVal.Index(i).Interface())
+		}
+		return lowered
+	}
+	return value
+}
@@ -0,0 +1,134 @@
+package filtersquirrel
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/require"
+	"github.com/xafelium/filter"
+	"testing"
+)
+
+type caseTestEmail string
+
+var caseTestPointer = "John@Example.com"
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestApplyFilterWithCaseSensitivity(t *testing.T) {
+	tests := []struct {
+		name         string
+		filter       filter.Condition
+		opts         []Option
+		expectedSql  string
+		expectedArgs []any
+	}{
+		{
+			name:         "case-insensitive equals",
+			filter:       filter.Where(filter.Equals("email", "John@Example.com")),
+			opts:         []Option{WithCaseSensitivity(CaseInsensitive, "email")},
+			expectedSql:  "SELECT * FROM users WHERE LOWER(email) = ?",
+			expectedArgs: []any{"john@example.com"},
+		},
+		{
+			name:         "case-insensitive not equals",
+			filter:       filter.Where(filter.NotEquals("email", "John@Example.com")),
+			opts:         []Option{WithCaseSensitivity(CaseInsensitive, "email")},
+			expectedSql:  "SELECT * FROM users WHERE LOWER(email) <> ?",
+			expectedArgs: []any{"john@example.com"},
+		},
+		{
+			name:         "case-insensitive in",
+			filter:       filter.Where(filter.In("email", []string{"A@x.com", "b@X.com"})),
+			opts:         []Option{WithCaseSensitivity(CaseInsensitive, "email")},
+			expectedSql:  "SELECT * FROM users WHERE LOWER(email) IN (?,?)",
+			expectedArgs: []any{"a@x.com", "b@x.com"},
+		},
+		{
+			name:         "case-insensitive in with any slice",
+			filter:       filter.Where(filter.In("email", []any{"A@x.com", 1})),
+			opts:         []Option{WithCaseSensitivity(CaseInsensitive, "email")},
+			expectedSql:  "SELECT * FROM users WHERE LOWER(email) IN (?,?)",
+			expectedArgs: []any{"a@x.com", 1},
+		},
+		{
+			name:         "case-insensitive equals with named string",
+			filter:       filter.Where(filter.Equals("email", caseTestEmail("John@Example.com"))),
+			opts:         []Option{WithCaseSensitivity(CaseInsensitive, "email")},
+			expectedSql:  "SELECT * FROM users WHERE LOWER(email) = ?",
+			expectedArgs: []any{"john@example.com"},
+		},
+		{
+			name:         "case-insensitive equals with string pointer",
+			filter:       filter.Where(filter.Equals("email", &caseTestPointer)),
+			opts:         []Option{WithCaseSensitivity(CaseInsensitive, "email")},
+			expectedSql:  "SELECT * FROM users WHERE LOWER(email) = ?",
+			expectedArgs: []any{"john@example.com"},
+		},
+		{
+			name:         "case-insensitive in with named strings",
+			filter:       filter.Where(filter.In("email", []caseTestEmail{"A@x.com", "b@X.com"})),
+			opts:         []Option{WithCaseSensitivity(CaseInsensitive, "email")},
+			expectedSql:  "SELECT * FROM users WHERE LOWER(email) IN (?,?)",
+			expectedArgs: []any{"a@x.com", "b@x.com"},
+		},
+		{
+			name:         "other fields keep their default",
+			filter:       filter.Where(filter.And(filter.Equals("name", "John"), filter.Contains("code", "AB"))),
+			opts:         []Option{WithCaseSensitivity(CaseInsensitive, "email")},
+			expectedSql:  "SELECT * FROM users WHERE (name = ? AND code ILIKE ?)",
+			expectedArgs: []any{"John", "%AB%"},
+		},
+		{
+			name:         "postgres case-sensitive contains",
+			filter:       filter.Where(filter.Contains("code", "AB")),
+			opts:         []Option{WithCaseSensitivity(CaseSensitive, "code")},
+			expectedSql:  "SELECT * FROM users WHERE code LIKE ?",
+			expectedArgs: []any{"%AB%"},
+		},
+		{
+			name:         "mysql case-sensitive contains",
+			filter:       filter.Where(filter.Contains("code", "AB")),
+			opts:         []Option{WithDialect(MySQL), WithCaseSensitivity(CaseSensitive, "code")},
+			expectedSql:  "SELECT * FROM users WHERE code COLLATE utf8mb4_bin LIKE ?",
+			expectedArgs: []any{"%AB%"},
+		},
+		{
+			name:         "mysql case-sensitive equals",
+			filter:       filter.Where(filter.Equals("code", "AB")),
+			opts:         []Option{WithDialect(MySQL), WithCaseSensitivity(CaseSensitive, "code")},
+			expectedSql:  "SELECT * FROM users WHERE code COLLATE utf8mb4_bin = ?",
+			expectedArgs: []any{"AB"},
+		},
+		{
+			name:         "sqlite case-sensitive starts with",
+			filter:       filter.Where(StartsWith("code", "A*_%")),
+			opts:         []Option{WithDialect(SQLite), WithCaseSensitivity(CaseSensitive, "code")},
+			expectedSql:  "SELECT * FROM users WHERE code GLOB ?",
+			expectedArgs: []any{"A[*]_%*"},
+		},
+		{
+			name:         "sqlserver case-sensitive in",
+			filter:       filter.Where(filter.In("code", []string{"AB", "cd"})),
+			opts:         []Option{WithDialect(SQLServer), WithCaseSensitivity(CaseSensitive, "code")},
+			expectedSql:  "SELECT * FROM users WHERE code COLLATE Latin1_General_CS_AS IN (?,?)",
+			expectedArgs: []any{"AB", "cd"},
+		},
+		{
+			name:         "sqlserver case-sensitive contains",
+			filter:       filter.Where(filter.Contains("code", "AB")),
+			opts:         []Option{WithDialect(SQLServer), WithCaseSensitivity(CaseSensitive, "code")},
+			expectedSql:  "SELECT * FROM users WHERE code COLLATE Latin1_General_CS_AS LIKE ? ESCAPE '\\'",
+			expectedArgs: []any{"%AB%"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			builder, _, err := ApplyFilter(sq.Select("*").From("users"), test.filter, test.opts...)
+
+			require.NoError(t, err)
+			sql, args, err := builder.ToSql()
+			require.NoError(t, err)
+			require.Equal(t, test.expectedSql, sql)
+			require.Equal(t, test.expectedArgs, args)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package filtersquirrel
+
+import "fmt"
+
+// Condition types provided by this package in addition to the ones of the filter package.
+const (
+	StartsWithConditionType    = "StartsWithCondition"
+	EndsWithConditionType      = "EndsWithCondition"
+	SearchConditionType        = "SearchCondition"
+	RangeContainsConditionType = "RangeContainsCondition"
+)
+
+// ConditionTypes returns the condition types provided by this package.
+func ConditionTypes() []string {
+	return []string{
+		StartsWithConditionType,
+		EndsWithConditionType,
+		SearchConditionType,
+		RangeContainsConditionType,
+	}
+}
+
+// StartsWithCondition filters strings starting with a value.
+type StartsWithCondition struct {
+	Field string
+	Value string
+}
+
+// StartsWith creates a new StartsWithCondition.
+func StartsWith(field string, value string) *StartsWithCondition {
+	return &StartsWithCondition{
+		Field: field,
+		Value: value,
+	}
+}
+
+// String returns the string representation of the condition.
+func (c *StartsWithCondition) String() string {
+	return fmt.Sprintf("%s starts with %s", c.Field, c.Value)
+}
+
+// Type returns the name of the condition.
+func (c *StartsWithCondition) Type() string {
+	return StartsWithConditionType
+}
+
+// EndsWithCondition filters strings ending with a value.
+type EndsWithCondition struct {
+	Field string
+	Value string
+}
+
+// EndsWith creates a new EndsWithCondition.
+func EndsWith(field string, value string) *EndsWithCondition {
+	return &EndsWithCondition{
+		Field: field,
+		Value: value,
+	}
+}
+
+// String returns the string representation of the condition.
+func (c *EndsWithCondition) String() string {
+	return fmt.Sprintf("%s ends with %s", c.Field, c.Value)
+}
+
+// Type returns the name of the condition.
+func (c *EndsWithCondition) Type() string {
+	return EndsWithConditionType
+}
+
+// SearchCondition filters texts with a full-text search for a query in the web search syntax of the database.
+type SearchCondition struct {
+	Field string
+	Query string
+}
+
+// Search creates a new SearchCondition.
+func Search(field string, query string) *SearchCondition {
+	return &SearchCondition{
+		Field: field,
+		Query: query,
+	}
+}
+
+// String returns the string representation of the condition.
+func (c *SearchCondition) String() string {
+	return fmt.Sprintf("%s matches %s", c.Field, c.Query)
+}
+
+// Type returns the name of the condition.
+func (c *SearchCondition) Type() string {
+	return SearchConditionType
+}
+
+// RangeContainsCondition filters ranges containing a value, e.g. the validity periods containing a point in time.
+type RangeContainsCondition struct {
+	Field string
+	Value any
+}
+
+// RangeContains creates a new RangeContainsCondition.
+func RangeContains(field string, value any) *RangeContainsCondition {
+	return &RangeContainsCondition{
+		Field: field,
+		Value: value,
+	}
+}
+
+// String returns the string representation of the condition.
+func (c *RangeContainsCondition) String() string {
+	return fmt.Sprintf("%s contains %v", c.Field, c.Value)
+}
+
+// Type returns the name of the condition.
+func (c *RangeContainsCondition) Type() string {
+	return RangeContainsConditionType
+}
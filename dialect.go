@@ -0,0 +1,544 @@
+package filtersquirrel
+
+import (
+	"errors"
+	"fmt"
+	sq "github.com/Masterminds/squirrel"
+	"strings"
+)
+
+// ErrUnsupported is returned when a condition cannot be expressed in the selected Dialect.
+var ErrUnsupported = errors.New("unsupported condition")
+
+// Dialect renders the parts of a condition which differ between databases.
+type Dialect interface {
+	// Name returns the name of the dialect.
+	Name() string
+	// EscapeLike escapes the LIKE wildcards in value, so it is matched literally.
+	EscapeLike(value string) string
+	// Like matches the field against a LIKE pattern escaped with EscapeLike.
+	Like(fieldName string, pattern string, caseSensitive bool) (sq.Sqlizer, error)
+	// CaseSensitive returns the field expression to compare it case-sensitively.
+	CaseSensitive(fieldName string) string
+	Regex(fieldName string, expression string) (sq.Sqlizer, error)
+	NotRegex(fieldName string, expression string) (sq.Sqlizer, error)
+	ArrayContains(fieldName string, value any) (sq.Sqlizer, error)
+	ArrayContainsArray(fieldName string, value any) (sq.Sqlizer, error)
+	ArrayIsContained(fieldName string, value any) (sq.Sqlizer, error)
+	Overlaps(fieldName string, value any) (sq.Sqlizer, error)
+	// OrderBy returns the ORDER BY expressions to sort by the column.
+	OrderBy(column string, direction SortDirection, nulls NullsOrder) []string
+	// Page orders the query by the ORDER BY expressions and selects limit rows after skipping offset rows.
+	Page(b sq.SelectBuilder, orderBys []string, limit uint64, offset uint64) sq.SelectBuilder
+	// SupportsRowValues reports whether row values like (a, b) > (?, ?) can be compared.
+	SupportsRowValues() bool
+	// SupportsArrayParameters reports whether lists can be bound as a single array parameter like = ANY (?).
+	SupportsArrayParameters() bool
+	// SupportsRangeTypes reports whether columns can be range types like tstzrange.
+	SupportsRangeTypes() bool
+	// IsDistinctFrom compares the field with a non-NULL value, matching NULL as a distinct value.
+	IsDistinctFrom(fieldName string, value any) sq.Sqlizer
+	// IsNotTrue matches if the condition is false or NULL.
+	IsNotTrue(condition sq.Sqlizer) sq.Sqlizer
+	// JSONPath returns the expression extracting the path from the JSON column and the value converted to compare
+	// with it. Numbers and booleans are extracted with their type, other values as text.
+	JSONPath(column string, path []string, value any) (string, any)
+	// JSONContains matches if the JSON column contains the JSON document.
+	JSONContains(column string, document string) (sq.Sqlizer, error)
+	// Search matches the field with a full-text search for the query. The language is empty for the default one.
+	Search(fieldName string, query string, language string) (sq.Sqlizer, error)
+	// JSONArray compares the elements of the JSON array column with the values, see WithJSONArray.
+	JSONArray(column string, operator ArrayOperator, values []any) (sq.Sqlizer, error)
+}
+
+var (
+	Postgres  Dialect = postgresDialect{}
+	MySQL     Dialect = mysqlDialect{withoutArrays{name: "mysql"}}
+	SQLite    Dialect = sqliteDialect{withoutArrays{name: "sqlite"}}
+	SQLServer Dialect = sqlServerDialect{withoutArrays{name: "sqlserver"}}
+)
+
+// likeEscapeChar is the escape character used in LIKE patterns.
+const likeEscapeChar = '\\'
+
+// escapeLike prefixes every occurrence of the special characters with likeEscapeChar.
+func escapeLike(value string, specials string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if r == likeEscapeChar || strings.ContainsRune(specials, r) {
+			b.WriteRune(likeEscapeChar)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// likeEscape renders LIKE with an explicit ESCAPE clause for databases without a default escape character.
+func likeEscape(fieldName string, pattern string) sq.Sqlizer {
+	return sq.Expr(fmt.Sprintf("%s LIKE ? ESCAPE '%c'", fieldName, likeEscapeChar), pattern)
+}
+
+// likeToGlob translates an escaped LIKE pattern into the equivalent GLOB pattern.
+func likeToGlob(pattern string) string {
+	var b strings.Builder
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			escaped = false
+		case r == likeEscapeChar:
+			escaped = true
+			continue
+		case r == '%':
+			b.WriteRune('*')
+			continue
+		case r == '_':
+			b.WriteRune('?')
+			continue
+		}
+		if strings.ContainsRune("*?[", r) {
+			b.WriteString("[" + string(r) + "]")
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// wrapped formats the SQL of the inner condition into an enclosing expression.
+type wrapped struct {
+	format string
+	inner  sq.Sqlizer
+}
+
+func (w wrapped) ToSql() (string, []interface{}, error) {
+	sql, args, err := w.inner.ToSql()
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf(w.format, sql), args, nil
+}
+
+func unsupported(dialect string, feature string) error {
+	return fmt.Errorf("%w: %s cannot be expressed in %s", ErrUnsupported, feature, dialect)
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string {
+	return "postgres"
+}
+
+func (postgresDialect) EscapeLike(value string) string {
+	return escapeLike(value, "%_")
+}
+
+func (postgresDialect) Like(fieldName string, pattern string, caseSensitive bool) (sq.Sqlizer, error) {
+	if caseSensitive {
+		return sq.Like{fieldName: pattern}, nil
+	}
+	return sq.ILike{fieldName: pattern}, nil
+}
+
+func (postgresDialect) CaseSensitive(fieldName string) string {
+	return fieldName
+}
+
+func (postgresDialect) OrderBy(column string, direction SortDirection, nulls NullsOrder) []string {
+	return orderByNulls(column, direction, nulls)
+}
+
+func (postgresDialect) Page(b sq.SelectBuilder, orderBys []string, limit uint64, offset uint64) sq.SelectBuilder {
+	return pageLimitOffset(b, orderBys, limit, offset)
+}
+
+func (postgresDialect) SupportsRowValues() bool {
+	return true
+}
+
+func (postgresDialect) SupportsArrayParameters() bool {
+	return true
+}
+
+func (postgresDialect) SupportsRangeTypes() bool {
+	return true
+}
+
+func (postgresDialect) IsDistinctFrom(fieldName string, value any) sq.Sqlizer {
+	return sq.Expr(fmt.Sprintf("%s IS DISTINCT FROM ?", fieldName), value)
+}
+
+func (postgresDialect) IsNotTrue(condition sq.Sqlizer) sq.Sqlizer {
+	return wrapped{format: "(%s) IS NOT TRUE", inner: condition}
+}
+
+func (postgresDialect) JSONPath(column string, path []string, value any) (string, any) {
+	switch jsonKindOf(value) {
+	case jsonNumber:
+		return fmt.Sprintf("(%s)::numeric", postgresJSONPath(column, path)), value
+	case jsonBool:
+		return fmt.Sprintf("(%s)::boolean", postgresJSONPath(column, path)), value
+	}
+	return postgresJSONPath(column, path), value
+}
+
+// JSONContains requires a jsonb column.
+func (postgresDialect) JSONContains(column string, document string) (sq.Sqlizer, error) {
+	return sq.Expr(fmt.Sprintf("%s @> ?::jsonb", column), document), nil
+}
+
+// JSONArray requires a jsonb column. Containment uses the jsonb operators, which are supported by GIN indexes.
+func (postgresDialect) JSONArray(column string, operator ArrayOperator, values []any) (sq.Sqlizer, error) {
+	document, hasNull, err := jsonArray(values)
+	if err != nil {
+		return nil, err
+	}
+	switch operator {
+	case ArrayContainsOperator:
+		if hasNull {
+			return sq.Expr(sqlFalse), nil
+		}
+		return sq.Expr(fmt.Sprintf("%s @> ?::jsonb", column), document), nil
+	case ArrayIsContainedOperator:
+		return sq.Expr(fmt.Sprintf("%s <@ ?::jsonb", column), document), nil
+	}
+	documents, err := jsonValues(values)
+	if err != nil {
+		return nil, err
+	}
+	elements := arrayElements{
+		from:        fmt.Sprintf("jsonb_array_elements(%s) AS elements(value)", column),
+		element:     "value",
+		placeholder: "?::jsonb",
+		column:      column,
+	}
+	return elements.predicate(operator, documents), nil
+}
+
+// Search renders the language literally, so the expression matches a to_tsvector index.
+func (postgresDialect) Search(fieldName string, query string, language string) (sq.Sqlizer, error) {
+	if language == "" {
+		return sq.Expr(fmt.Sprintf("to_tsvector(%s) @@ websearch_to_tsquery(?)", fieldName), query), nil
+	}
+	return sq.Expr(fmt.Sprintf("to_tsvector('%[2]s', %[1]s) @@ websearch_to_tsquery('%[2]s', ?)", fieldName, language), query), nil
+}
+
+func (postgresDialect) Regex(fieldName string, expression string) (sq.Sqlizer, error) {
+	return &Regex{fieldName: fieldName, expression: expression}, nil
+}
+
+func (postgresDialect) NotRegex(fieldName string, expression string) (sq.Sqlizer, error) {
+	return &NotRegex{fieldName: fieldName, expression: expression}, nil
+}
+
+func (postgresDialect) ArrayContains(fieldName string, value any) (sq.Sqlizer, error) {
+	return &ArrayContains{fieldName: fieldName, value: value}, nil
+}
+
+func (postgresDialect) ArrayContainsArray(fieldName string, value any) (sq.Sqlizer, error) {
+	return &ArrayContainsArray{fieldName: fieldName, value: value}, nil
+}
+
+func (postgresDialect) ArrayIsContained(fieldName string, value any) (sq.Sqlizer, error) {
+	return &ArrayIsContained{fieldName: fieldName, value: value}, nil
+}
+
+func (postgresDialect) Overlaps(fieldName string, value any) (sq.Sqlizer, error) {
+	return &Overlaps{fieldName: fieldName, value: value}, nil
+}
+
+// withoutArrays rejects the array conditions for databases without native array and range types.
+type withoutArrays struct {
+	name string
+}
+
+func (d withoutArrays) Name() string {
+	return d.name
+}
+
+func (withoutArrays) SupportsArrayParameters() bool {
+	return false
+}
+
+func (withoutArrays) SupportsRangeTypes() bool {
+	return false
+}
+
+func (d withoutArrays) ArrayContains(string, any) (sq.Sqlizer, error) {
+	return nil, unsupported(d.name, "ArrayContains")
+}
+
+func (d withoutArrays) ArrayContainsArray(string, any) (sq.Sqlizer, error) {
+	return nil, unsupported(d.name, "ArrayContainsArray")
+}
+
+func (d withoutArrays) ArrayIsContained(string, any) (sq.Sqlizer, error) {
+	return nil, unsupported(d.name, "ArrayIsContained")
+}
+
+func (d withoutArrays) Overlaps(string, any) (sq.Sqlizer, error) {
+	return nil, unsupported(d.name, "Overlaps")
+}
+
+type mysqlDialect struct {
+	withoutArrays
+}
+
+func (mysqlDialect) EscapeLike(value string) string {
+	return escapeLike(value, "%_")
+}
+
+// Like relies on the case-insensitive default collations of MySQL.
+func (d mysqlDialect) Like(fieldName string, pattern string, caseSensitive bool) (sq.Sqlizer, error) {
+	if caseSensitive {
+		fieldName = d.CaseSensitive(fieldName)
+	}
+	return sq.Like{fieldName: pattern}, nil
+}
+
+func (mysqlDialect) CaseSensitive(fieldName string) string {
+	return fmt.Sprintf("%s COLLATE utf8mb4_bin", fieldName)
+}
+
+func (mysqlDialect) OrderBy(column string, direction SortDirection, nulls NullsOrder) []string {
+	return orderByNullsEmulated(column, direction, nulls)
+}
+
+func (mysqlDialect) Page(b sq.SelectBuilder, orderBys []string, limit uint64, offset uint64) sq.SelectBuilder {
+	return pageLimitOffset(b, orderBys, limit, offset)
+}
+
+func (mysqlDialect) SupportsRowValues() bool {
+	return true
+}
+
+// IsDistinctFrom negates the NULL-safe equality operator of MySQL.
+func (mysqlDialect) IsDistinctFrom(fieldName string, value any) sq.Sqlizer {
+	return sq.Expr(fmt.Sprintf("NOT (%s <=> ?)", fieldName), value)
+}
+
+func (mysqlDialect) IsNotTrue(condition sq.Sqlizer) sq.Sqlizer {
+	return wrapped{format: "(%s) IS NOT TRUE", inner: condition}
+}
+
+// JSONPath compares numbers with the extracted JSON value, which MySQL compares numerically.
+// Booleans are compared as text, as they are bound as integers.
+func (mysqlDialect) JSONPath(column string, path []string, value any) (string, any) {
+	extract := fmt.Sprintf("JSON_EXTRACT(%s, '%s')", column, jsonPathExpression(path))
+	switch jsonKindOf(value) {
+	case jsonNumber:
+		return extract, value
+	case jsonBool:
+		value = formatBools(value)
+	}
+	return fmt.Sprintf("JSON_UNQUOTE(%s)", extract), value
+}
+
+func (mysqlDialect) JSONContains(column string, document string) (sq.Sqlizer, error) {
+	return sq.Expr(fmt.Sprintf("JSON_CONTAINS(%s, ?)", column), document), nil
+}
+
+// JSONArray compares the elements as JSON with JSON_CONTAINS and JSON_OVERLAPS, which requires MySQL 8.0.17.
+func (mysqlDialect) JSONArray(column string, operator ArrayOperator, values []any) (sq.Sqlizer, error) {
+	document, hasNull, err := jsonArray(values)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case operator == ArrayContainsOperator && hasNull:
+		return sq.Expr(sqlFalse), nil
+	case operator == ArrayContainsOperator:
+		return sq.Expr(fmt.Sprintf("JSON_CONTAINS(%s, ?)", column), document), nil
+	case operator == ArrayIsContainedOperator:
+		return sq.Expr(fmt.Sprintf("JSON_CONTAINS(?, %s)", column), document), nil
+	case document == "[]":
+		return sq.Expr(sqlFalse), nil
+	}
+	return sq.Expr(fmt.Sprintf("JSON_OVERLAPS(%s, ?)", column), document), nil
+}
+
+// Search requires a FULLTEXT index on the field, whose parser determines the language.
+func (mysqlDialect) Search(fieldName string, query string, _ string) (sq.Sqlizer, error) {
+	return sq.Expr(fmt.Sprintf("MATCH (%s) AGAINST (? IN NATURAL LANGUAGE MODE)", fieldName), query), nil
+}
+
+func (mysqlDialect) Regex(fieldName string, expression string) (sq.Sqlizer, error) {
+	return sq.Expr(fmt.Sprintf("%s REGEXP ?", fieldName), expression), nil
+}
+
+func (mysqlDialect) NotRegex(fieldName string, expression string) (sq.Sqlizer, error) {
+	return sq.Expr(fmt.Sprintf("%s NOT REGEXP ?", fieldName), expression), nil
+}
+
+type sqliteDialect struct {
+	withoutArrays
+}
+
+func (sqliteDialect) EscapeLike(value string) string {
+	return escapeLike(value, "%_")
+}
+
+// Like relies on LIKE being case-insensitive for ASCII characters in SQLite.
+// Case-sensitive patterns are translated to GLOB, as LIKE ignores collations.
+func (sqliteDialect) Like(fieldName string, pattern string, caseSensitive bool) (sq.Sqlizer, error) {
+	if caseSensitive {
+		return sq.Expr(fmt.Sprintf("%s GLOB ?", fieldName), likeToGlob(pattern)), nil
+	}
+	return likeEscape(fieldName, pattern), nil
+}
+
+func (sqliteDialect) CaseSensitive(fieldName string) string {
+	return fieldName
+}
+
+// OrderBy requires SQLite 3.30 or newer for NULLS FIRST and NULLS LAST.
+func (sqliteDialect) OrderBy(column string, direction SortDirection, nulls NullsOrder) []string {
+	return orderByNulls(column, direction, nulls)
+}
+
+func (sqliteDialect) Page(b sq.SelectBuilder, orderBys []string, limit uint64, offset uint64) sq.SelectBuilder {
+	return pageLimitOffset(b, orderBys, limit, offset)
+}
+
+func (sqliteDialect) SupportsRowValues() bool {
+	return true
+}
+
+// IsDistinctFrom uses IS NOT, which compares NULL values like IS DISTINCT FROM in SQLite.
+func (sqliteDialect) IsDistinctFrom(fieldName string, value any) sq.Sqlizer {
+	return sq.Expr(fmt.Sprintf("%s IS NOT ?", fieldName), value)
+}
+
+// IsNotTrue requires SQLite 3.23 or newer.
+func (sqliteDialect) IsNotTrue(condition sq.Sqlizer) sq.Sqlizer {
+	return wrapped{format: "(%s) IS NOT TRUE", inner: condition}
+}
+
+// JSONPath relies on json_extract returning SQL values of the JSON type, booleans as 1 and 0.
+func (sqliteDialect) JSONPath(column string, path []string, value any) (string, any) {
+	return fmt.Sprintf("json_extract(%s, '%s')", column, jsonPathExpression(path)), value
+}
+
+func (d sqliteDialect) JSONContains(string, string) (sq.Sqlizer, error) {
+	return nil, unsupported(d.name, "JSON containment")
+}
+
+// JSONArray compares the elements returned by json_each as SQL values, booleans as 1 and 0.
+func (sqliteDialect) JSONArray(column string, operator ArrayOperator, values []any) (sq.Sqlizer, error) {
+	elements := arrayElements{
+		from:        fmt.Sprintf("json_each(%s)", column),
+		element:     "value",
+		placeholder: "?",
+		column:      column,
+	}
+	return elements.predicate(operator, values), nil
+}
+
+// Search requires the field to be a column of an FTS5 table, whose tokenizer determines the language.
+func (sqliteDialect) Search(fieldName string, query string, _ string) (sq.Sqlizer, error) {
+	return sq.Expr(fmt.Sprintf("%s MATCH ?", fieldName), fts5Query(query)), nil
+}
+
+// Regex requires a regexp() function to be registered with the SQLite connection.
+func (sqliteDialect) Regex(fieldName string, expression string) (sq.Sqlizer, error) {
+	return sq.Expr(fmt.Sprintf("%s REGEXP ?", fieldName), expression), nil
+}
+
+func (sqliteDialect) NotRegex(fieldName string, expression string) (sq.Sqlizer, error) {
+	return sq.Expr(fmt.Sprintf("%s NOT REGEXP ?", fieldName), expression), nil
+}
+
+type sqlServerDialect struct {
+	withoutArrays
+}
+
+// EscapeLike escapes the character classes of SQL Server in addition to the standard wildcards.
+func (sqlServerDialect) EscapeLike(value string) string {
+	return escapeLike(value, "%_[")
+}
+
+// Like relies on the case-insensitive default collations of SQL Server.
+func (d sqlServerDialect) Like(fieldName string, pattern string, caseSensitive bool) (sq.Sqlizer, error) {
+	if caseSensitive {
+		fieldName = d.CaseSensitive(fieldName)
+	}
+	return likeEscape(fieldName, pattern), nil
+}
+
+func (sqlServerDialect) CaseSensitive(fieldName string) string {
+	return fmt.Sprintf("%s COLLATE Latin1_General_CS_AS", fieldName)
+}
+
+func (sqlServerDialect) OrderBy(column string, direction SortDirection, nulls NullsOrder) []string {
+	return orderByNullsEmulated(column, direction, nulls)
+}
+
+// Page uses OFFSET and FETCH, as SQL Server has no LIMIT. They require an ORDER BY clause, so unordered queries
+// are ordered by (SELECT NULL).
+func (sqlServerDialect) Page(b sq.SelectBuilder, orderBys []string, limit uint64, offset uint64) sq.SelectBuilder {
+	if len(orderBys) == 0 {
+		orderBys = []string{"(SELECT NULL)"}
+	}
+	return b.OrderBy(orderBys...).Suffix(fmt.Sprintf("OFFSET %d ROWS FETCH NEXT %d ROWS ONLY", offset, limit))
+}
+
+func (sqlServerDialect) SupportsRowValues() bool {
+	return false
+}
+
+// IsDistinctFrom checks for NULL explicitly, as IS DISTINCT FROM requires SQL Server 2022.
+func (sqlServerDialect) IsDistinctFrom(fieldName string, value any) sq.Sqlizer {
+	return sq.Or{sq.NotEq{fieldName: value}, sq.Eq{fieldName: nil}}
+}
+
+// IsNotTrue uses CASE, as SQL Server has no boolean expressions.
+func (sqlServerDialect) IsNotTrue(condition sq.Sqlizer) sq.Sqlizer {
+	return wrapped{format: "CASE WHEN %s THEN 1 ELSE 0 END = 0", inner: condition}
+}
+
+// JSONPath casts numbers, booleans are compared as the text returned by JSON_VALUE.
+func (sqlServerDialect) JSONPath(column string, path []string, value any) (string, any) {
+	extract := fmt.Sprintf("JSON_VALUE(%s, '%s')", column, jsonPathExpression(path))
+	switch jsonKindOf(value) {
+	case jsonNumber:
+		return fmt.Sprintf("CAST(%s AS FLOAT)", extract), value
+	case jsonBool:
+		value = formatBools(value)
+	}
+	return extract, value
+}
+
+func (d sqlServerDialect) JSONContains(string, string) (sq.Sqlizer, error) {
+	return nil, unsupported(d.name, "JSON containment")
+}
+
+// JSONArray compares the elements returned by OPENJSON as text, e.g. numbers as 1.5 and booleans as true.
+func (sqlServerDialect) JSONArray(column string, operator ArrayOperator, values []any) (sq.Sqlizer, error) {
+	texts, err := jsonTexts(values)
+	if err != nil {
+		return nil, err
+	}
+	elements := arrayElements{
+		from:        fmt.Sprintf("OPENJSON(%s)", column),
+		element:     "value",
+		placeholder: "?",
+		column:      column,
+	}
+	return elements.predicate(operator, texts), nil
+}
+
+// Search requires a full-text index on the field.
+func (sqlServerDialect) Search(fieldName string, query string, language string) (sq.Sqlizer, error) {
+	if language == "" {
+		return sq.Expr(fmt.Sprintf("FREETEXT(%s, ?)", fieldName), query), nil
+	}
+	return sq.Expr(fmt.Sprintf("FREETEXT(%s, ?, LANGUAGE '%s')", fieldName, language), query), nil
+}
+
+func (d sqlServerDialect) Regex(string, string) (sq.Sqlizer, error) {
+	return nil, unsupported(d.name, "Regex")
+}
+
+func (d sqlServerDialect) NotRegex(string, string) (sq.Sqlizer, error) {
+	return nil, unsupported(d.name, "NotRegex")
+}
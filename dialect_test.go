@@ -0,0 +1,245 @@
+package filtersquirrel
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/require"
+	"github.com/xafelium/filter"
+	"testing"
+)
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestApplyFilterWithDialect(t *testing.T) {
+	tests := []struct {
+		name         string
+		dialect      Dialect
+		filter       filter.Condition
+		builder      sq.SelectBuilder
+		expectedSql  string
+		expectedArgs []any
+		errContains  string
+	}{
+		{
+			name:         "postgres contains",
+			dialect:      Postgres,
+			filter:       filter.Where(filter.Contains("label", "abc")),
+			builder:      sq.Select("*").From("g").PlaceholderFormat(sq.Dollar),
+			expectedSql:  "SELECT * FROM g WHERE label ILIKE $1",
+			expectedArgs: []any{"%abc%"},
+		},
+		{
+			name:         "mysql contains",
+			dialect:      MySQL,
+			filter:       filter.Where(filter.Contains("label", "abc")),
+			builder:      sq.Select("*").From("g"),
+			expectedSql:  "SELECT * FROM g WHERE label LIKE ?",
+			expectedArgs: []any{"%abc%"},
+		},
+		{
+			name:         "mysql regex",
+			dialect:      MySQL,
+			filter:       filter.Where(filter.Regex("name", "frau$")),
+			builder:      sq.Select("*").From("users"),
+			expectedSql:  "SELECT * FROM users WHERE name REGEXP ?",
+			expectedArgs: []any{"frau$"},
+		},
+		{
+			name:         "mysql not regex",
+			dialect:      MySQL,
+			filter:       filter.Where(filter.NotRegex("name", "mann$")),
+			builder:      sq.Select("*").From("users"),
+			expectedSql:  "SELECT * FROM users WHERE name NOT REGEXP ?",
+			expectedArgs: []any{"mann$"},
+		},
+		{
+			name:        "mysql array contains",
+			dialect:     MySQL,
+			filter:      filter.Where(filter.ArrayContains("ids", 4)),
+			errContains: "ArrayContains cannot be expressed in mysql",
+		},
+		{
+			name:         "sqlite contains",
+			dialect:      SQLite,
+			filter:       filter.Where(filter.Contains("label", "abc")),
+			builder:      sq.Select("*").From("g"),
+			expectedSql:  "SELECT * FROM g WHERE label LIKE ? ESCAPE '\\'",
+			expectedArgs: []any{"%abc%"},
+		},
+		{
+			name:         "sqlite contains with wildcards",
+			dialect:      SQLite,
+			filter:       filter.Where(filter.Contains("label", "50%_\\")),
+			builder:      sq.Select("*").From("g"),
+			expectedSql:  "SELECT * FROM g WHERE label LIKE ? ESCAPE '\\'",
+			expectedArgs: []any{"%50\\%\\_\\\\%"},
+		},
+		{
+			name:         "sqlite starts with",
+			dialect:      SQLite,
+			filter:       filter.Where(StartsWith("label", "ab_")),
+			builder:      sq.Select("*").From("g"),
+			expectedSql:  "SELECT * FROM g WHERE label LIKE ? ESCAPE '\\'",
+			expectedArgs: []any{"ab\\_%"},
+		},
+		{
+			name:         "sqlite regex",
+			dialect:      SQLite,
+			filter:       filter.Where(filter.Regex("name", "frau$")),
+			builder:      sq.Select("*").From("users"),
+			expectedSql:  "SELECT * FROM users WHERE name REGEXP ?",
+			expectedArgs: []any{"frau$"},
+		},
+		{
+			name:        "sqlite overlaps",
+			dialect:     SQLite,
+			filter:      filter.Where(filter.Overlaps("tags", []int{1, 2})),
+			errContains: "Overlaps cannot be expressed in sqlite",
+		},
+		{
+			name:         "sqlserver contains",
+			dialect:      SQLServer,
+			filter:       filter.Where(filter.Contains("label", "abc")),
+			builder:      sq.Select("*").From("g").PlaceholderFormat(sq.AtP),
+			expectedSql:  "SELECT * FROM g WHERE label LIKE @p1 ESCAPE '\\'",
+			expectedArgs: []any{"%abc%"},
+		},
+		{
+			name:         "sqlserver contains with character class",
+			dialect:      SQLServer,
+			filter:       filter.Where(filter.Contains("label", "[a-z]%")),
+			builder:      sq.Select("*").From("g"),
+			expectedSql:  "SELECT * FROM g WHERE label LIKE ? ESCAPE '\\'",
+			expectedArgs: []any{"%\\[a-z]\\%%"},
+		},
+		{
+			name:         "mysql ends with",
+			dialect:      MySQL,
+			filter:       filter.Where(EndsWith("email", "@example.com")),
+			builder:      sq.Select("*").From("users"),
+			expectedSql:  "SELECT * FROM users WHERE email LIKE ?",
+			expectedArgs: []any{"%@example.com"},
+		},
+		{
+			name:        "sqlserver regex",
+			dialect:     SQLServer,
+			filter:      filter.Where(filter.Regex("name", "frau$")),
+			errContains: "Regex cannot be expressed in sqlserver",
+		},
+		{
+			name:        "sqlserver arrays overlap",
+			dialect:     SQLServer,
+			filter:      filter.Where(filter.ArraysOverlap("tags", []int{1, 2})),
+			errContains: "Overlaps cannot be expressed in sqlserver",
+		},
+		{
+			name:         "portable conditions",
+			dialect:      SQLServer,
+			filter:       filter.Where(filter.And(filter.Equals("a", 1), filter.In("b", []int{2, 3}), filter.IsNil("c"))),
+			builder:      sq.Select("*").From("t"),
+			expectedSql:  "SELECT * FROM t WHERE (a = ? AND b IN (?,?) AND c IS NULL)",
+			expectedArgs: []any{1, 2, 3},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			builder, _, err := ApplyFilter(test.builder, test.filter, WithDialect(test.dialect))
+
+			if test.errContains != "" {
+				require.ErrorIs(t, err, ErrUnsupported)
+				require.ErrorContains(t, err, test.errContains)
+			} else {
+				require.NoError(t, err)
+				sql, args, err := builder.ToSql()
+				require.NoError(t, err)
+				require.Equal(t, test.expectedSql, sql)
+				require.Equal(t, test.expectedArgs, args)
+			}
+		})
+	}
+}
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestApplyFilterWithNullAwareNegation(t *testing.T) {
+	tests := []struct {
+		name         string
+		dialect      Dialect
+		filter       filter.Condition
+		expectedSql  string
+		expectedArgs []any
+	}{
+		{
+			name:         "postgres not equals",
+			dialect:      Postgres,
+			filter:       filter.Where(filter.NotEquals("status", "archived")),
+			expectedSql:  "SELECT * FROM t WHERE status IS DISTINCT FROM ?",
+			expectedArgs: []any{"archived"},
+		},
+		{
+			name:        "postgres not equals nil",
+			dialect:     Postgres,
+			filter:      filter.Where(filter.NotEquals("status", nil)),
+			expectedSql: "SELECT * FROM t WHERE status IS NOT NULL",
+		},
+		{
+			name:         "postgres not equals list",
+			dialect:      Postgres,
+			filter:       filter.Where(filter.NotEquals("status", []string{"archived", "deleted"})),
+			expectedSql:  "SELECT * FROM t WHERE (status NOT IN (?,?) OR status IS NULL)",
+			expectedArgs: []any{"archived", "deleted"},
+		},
+		{
+			name:         "postgres not",
+			dialect:      Postgres,
+			filter:       filter.Where(filter.Not(filter.And(filter.Equals("status", "archived"), filter.GreaterThan("age", 3)))),
+			expectedSql:  "SELECT * FROM t WHERE ((status = ? AND age > ?)) IS NOT TRUE",
+			expectedArgs: []any{"archived", 3},
+		},
+		{
+			name:         "mysql not equals",
+			dialect:      MySQL,
+			filter:       filter.Where(filter.NotEquals("status", "archived")),
+			expectedSql:  "SELECT * FROM t WHERE NOT (status <=> ?)",
+			expectedArgs: []any{"archived"},
+		},
+		{
+			name:         "mysql not",
+			dialect:      MySQL,
+			filter:       filter.Where(filter.Not(filter.Equals("status", "archived"))),
+			expectedSql:  "SELECT * FROM t WHERE (status = ?) IS NOT TRUE",
+			expectedArgs: []any{"archived"},
+		},
+		{
+			name:         "sqlite not equals",
+			dialect:      SQLite,
+			filter:       filter.Where(filter.NotEquals("status", "archived")),
+			expectedSql:  "SELECT * FROM t WHERE status IS NOT ?",
+			expectedArgs: []any{"archived"},
+		},
+		{
+			name:         "sqlserver not equals",
+			dialect:      SQLServer,
+			filter:       filter.Where(filter.NotEquals("status", "archived")),
+			expectedSql:  "SELECT * FROM t WHERE (status <> ? OR status IS NULL)",
+			expectedArgs: []any{"archived"},
+		},
+		{
+			name:         "sqlserver not",
+			dialect:      SQLServer,
+			filter:       filter.Where(filter.Not(filter.Equals("status", "archived"))),
+			expectedSql:  "SELECT * FROM t WHERE CASE WHEN status = ? THEN 1 ELSE 0 END = 0",
+			expectedArgs: []any{"archived"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			builder, _, err := ApplyFilter(sq.Select("*").From("t"), test.filter,
+				WithDialect(test.dialect), WithNullAwareNegation(true))
+			require.NoError(t, err)
+			sql, args, err := builder.ToSql()
+			require.NoError(t, err)
+			require.Equal(t, test.expectedSql, sql)
+			require.Equal(t, test.expectedArgs, args)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package filtersquirrel
+
+import (
+	"errors"
+	"fmt"
+	"github.com/xafelium/filter"
+)
+
+// Sentinel errors to match the errors of ApplyFilter with errors.Is.
+var (
+	// ErrUnknownCondition is returned for conditions without registered builder.
+	ErrUnknownCondition = errors.New("unknown condition")
+	// ErrUnknownField is returned if the FieldMapperFunc cannot map a field.
+	ErrUnknownField = errors.New("unknown field")
+	// ErrInvalidValue is returned for values which are invalid for their field or condition.
+	ErrInvalidValue = errors.New("invalid value")
+	// ErrTooComplex is returned if a filter exceeds one of the complexity limits, see LimitError.
+	ErrTooComplex = errors.New("filter too complex")
+)
+
+// ConditionError is returned if a condition cannot be translated.
+type ConditionError struct {
+	// Path is the position of the condition in the tree, e.g. "where.and[2].or[0]".
+	Path          string
+	ConditionType string
+	// Field is the field name of a comparison condition, otherwise it is empty.
+	Field string
+	Err   error
+}
+
+func (e *ConditionError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Err)
+}
+
+func (e *ConditionError) Unwrap() error {
+	return e.Err
+}
+
+// mapperFunc calls the FieldMapperFunc and wraps its errors in ErrUnknownField.
+func (ctx *ApplyContext) mapperFunc(field string) (string, error) {
+	column, err := ctx.options.MapperFunc(field)
+	if err != nil && !errors.Is(err, ErrUnknownField) {
+		return "", fmt.Errorf("%w %s: %w", ErrUnknownField, field, err)
+	}
+	return column, err
+}
+
+// conditionError wraps the error of the condition in a ConditionError unless a nested condition already did.
+func (ctx *ApplyContext) conditionError(condition filter.Condition, err error) error {
+	var conditionErr *ConditionError
+	if errors.As(err, &conditionErr) {
+		return err
+	}
+	field, _ := conditionField(condition)
+	return &ConditionError{
+		Path:          displayPath(condition, ctx.path()),
+		ConditionType: condition.Type(),
+		Field:         field,
+		Err:           err,
+	}
+}
+
+// pathFrame is a condition being translated and the path segments of its children.
+type pathFrame struct {
+	path     string
+	children []childCondition
+	next     int
+}
+
+// enterPath pushes the condition on the path. The children of the logical conditions are translated in order,
+// so the n-th nested condition translated is the n-th child. Conditions nested in custom conditions are named
+// after their type.
+func (ctx *ApplyContext) enterPath(condition filter.Condition) {
+	path := ""
+	if n := len(ctx.frames); n > 0 {
+		parent := &ctx.frames[n-1]
+		segment := conditionName(condition)
+		if parent.next < len(parent.children) {
+			segment = parent.children[parent.next].segment
+		}
+		parent.next++
+		path = joinPath(parent.path, segment)
+	}
+	ctx.frames = append(ctx.frames, pathFrame{path: path, children: conditionChildren(condition)})
+}
+
+func (ctx *ApplyContext) leavePath() {
+	ctx.frames = ctx.frames[:len(ctx.frames)-1]
+}
+
+// path returns the path of the condition currently translated.
+func (ctx *ApplyContext) path() string {
+	if len(ctx.frames) == 0 {
+		return ""
+	}
+	return ctx.frames[len(ctx.frames)-1].path
+}
@@ -0,0 +1,87 @@
+package filtersquirrel
+
+import (
+	"errors"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/require"
+	"github.com/xafelium/filter"
+	"testing"
+)
+
+func TestConditionError(t *testing.T) {
+	mapperFunc := func(fieldName string) (string, error) {
+		if fieldName == "password" {
+			return "", errors.New("field is secret")
+		}
+		return fieldName, nil
+	}
+
+	tests := []struct {
+		name     string
+		filter   filter.Condition
+		opts     []Option
+		expected *ConditionError
+		sentinel error
+		message  string
+	}{
+		{
+			name: "unknown condition",
+			filter: filter.Where(filter.And(
+				filter.Equals("a", 1),
+				filter.Or(filter.Equals("b", 2), &withinRadiusCondition{Field: "location"}),
+			)),
+			expected: &ConditionError{Path: "where.and[1].or[1]", ConditionType: withinRadiusConditionType, Field: "location"},
+			sentinel: ErrUnknownCondition,
+			message:  "where.and[1].or[1]: unknown condition: WithinRadiusCondition",
+		},
+		{
+			name:     "mapper error",
+			filter:   filter.Where(filter.Not(filter.Equals("password", "x"))),
+			expected: &ConditionError{Path: "where.not", ConditionType: filter.EqualsConditionType, Field: "password"},
+			sentinel: ErrUnknownField,
+			message:  "where.not: unknown field password: field is secret",
+		},
+		{
+			name:     "invalid value",
+			filter:   filter.Where(filter.Group(filter.GreaterThan("age", "old"))),
+			opts:     []Option{WithFieldType(IntType, "age")},
+			sentinel: ErrInvalidValue,
+			message:  `invalid filter: where.group: invalid value for field age: "old" is no integer`,
+		},
+		{
+			name:     "too complex",
+			filter:   filter.Where(filter.And(filter.Equals("a", 1), filter.Equals("b", 2), filter.Equals("c", 3))),
+			opts:     []Option{WithMaxConditions(2)},
+			expected: &ConditionError{Path: "where.and[2]", ConditionType: filter.EqualsConditionType, Field: "c"},
+			sentinel: ErrTooComplex,
+			message:  "where.and[2]: filter too complex: conditions 3 exceeds maximum of 2",
+		},
+		{
+			name:     "root condition",
+			filter:   filter.Or(filter.Equals("a", 1)),
+			expected: &ConditionError{Path: "or", ConditionType: filter.OrConditionType},
+			message:  "or: OR condition must have at least two conditions",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := append([]Option{WithMapperFunc(mapperFunc)}, test.opts...)
+			_, _, err := ApplyFilter(sq.Select("*").From("t"), test.filter, opts...)
+			require.EqualError(t, err, test.message)
+			if test.sentinel != nil {
+				require.ErrorIs(t, err, test.sentinel)
+			}
+
+			var conditionErr *ConditionError
+			if test.expected == nil {
+				require.False(t, errors.As(err, &conditionErr))
+				return
+			}
+			require.ErrorAs(t, err, &conditionErr)
+			require.Equal(t, test.expected.Path, conditionErr.Path)
+			require.Equal(t, test.expected.ConditionType, conditionErr.ConditionType)
+			require.Equal(t, test.expected.Field, conditionErr.Field)
+		})
+	}
+}
@@ -0,0 +1,570 @@
+package filtersquirrel
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"github.com/xafelium/filter"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// FieldAccessorFunc returns the value of a domain field of an in-memory object.
+type FieldAccessorFunc func(field string) (any, error)
+
+// MapAccessor accesses the fields of a map. Missing keys are reported as errors.
+func MapAccessor(m map[string]any) FieldAccessorFunc {
+	return func(field string) (any, error) {
+		v, ok := m[field]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownField, field)
+		}
+		return v, nil
+	}
+}
+
+// StructAccessor accesses the fields of a struct by the names declared in its tags, see StructMapper.
+// Fields of nil nested structs are NULL, like the columns of an unmatched LEFT JOIN.
+func StructAccessor(v any) (FieldAccessorFunc, error) {
+	m, err := NewStructMapper(v)
+	if err != nil {
+		return nil, err
+	}
+	return m.Accessor(v), nil
+}
+
+// Accessor returns a FieldAccessorFunc for v, which must be of the struct type the StructMapper was created for.
+func (m *StructMapper) Accessor(v any) FieldAccessorFunc {
+	return func(field string) (any, error) {
+		index, ok := m.indexes[field]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownField, field)
+		}
+		fv := reflect.ValueOf(v)
+		for _, i := range index {
+			for fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					return nil, nil
+				}
+				fv = fv.Elem()
+			}
+			fv = fv.Field(i)
+		}
+		if !fv.CanInterface() {
+			return nil, fmt.Errorf("field %s is not accessible", field)
+		}
+		return fv.Interface(), nil
+	}
+}
+
+// Evaluate evaluates the condition against an in-memory object with the same semantics as the SQL generated by
+// ApplyFilter for PostgreSQL, including the three-valued logic of NULL values: a comparison with NULL is unknown,
+// which is neither matched by the condition nor by its negation, unless WithNullAwareNegation is set.
+func Evaluate(condition filter.Condition, accessor FieldAccessorFunc, opts ...Option) (bool, error) {
+	if condition == nil {
+		return true, nil
+	}
+	e := &evaluator{accessor: accessor, options: FromDefaultOptions(opts...)}
+	result, err := e.eval(condition)
+	if err != nil {
+		return false, err
+	}
+	return result == triTrue, nil
+}
+
+// tristate is a boolean of SQL's three-valued logic.
+type tristate int8
+
+const (
+	triUnknown tristate = iota
+	triFalse
+	triTrue
+)
+
+func triOf(b bool) tristate {
+	if b {
+		return triTrue
+	}
+	return triFalse
+}
+
+func (t tristate) not() tristate {
+	switch t {
+	case triTrue:
+		return triFalse
+	case triFalse:
+		return triTrue
+	}
+	return triUnknown
+}
+
+type evaluator struct {
+	accessor FieldAccessorFunc
+	options  *Options
+}
+
+func (e *evaluator) eval(condition filter.Condition) (tristate, error) {
+	switch c := condition.(type) {
+	case *filter.WhereCondition:
+		if c.Condition == nil {
+			return triTrue, nil
+		}
+		return e.eval(c.Condition)
+	case *filter.GroupCondition:
+		if c.Condition == nil {
+			return triTrue, nil
+		}
+		return e.eval(c.Condition)
+	case *filter.NotCondition:
+		inner, err := e.eval(c.Condition)
+		if e.options.NullAwareNegation {
+			return triOf(inner != triTrue), err
+		}
+		return inner.not(), err
+	case *filter.AndCondition:
+		if len(c.Conditions) < 2 {
+			return triUnknown, fmt.Errorf("AND condition must have at least two conditions")
+		}
+		return e.evalConjunction(c.Conditions, triFalse)
+	case *filter.OrCondition:
+		if len(c.Conditions) < 2 {
+			return triUnknown, fmt.Errorf("OR condition must have at least two conditions")
+		}
+		return e.evalConjunction(c.Conditions, triTrue)
+	case *filter.EqualsCondition:
+		return e.evalField(c.Field, func(v any) (tristate, error) { return e.evalEquals(c.Field, v, c.Value) })
+	case *filter.NotEqualsCondition:
+		if c.Value == nil {
+			return e.evalField(c.Field, func(v any) (tristate, error) { return triOf(!isNull(v)), nil })
+		}
+		return e.evalField(c.Field, func(v any) (tristate, error) {
+			if e.options.NullAwareNegation && isNull(v) {
+				return triTrue, nil
+			}
+			r, err := e.evalEquals(c.Field, v, c.Value)
+			return r.not(), err
+		})
+	case *filter.InCondition:
+		return e.evalField(c.Field, func(v any) (tristate, error) { return e.evalEquals(c.Field, v, listValue(c.Value)) })
+	case *filter.IsNilCondition:
+		return e.evalField(c.Field, func(v any) (tristate, error) { return triOf(isNull(v)), nil })
+	case *filter.NotNilCondition:
+		return e.evalField(c.Field, func(v any) (tristate, error) { return triOf(!isNull(v)), nil })
+	case *filter.GreaterThanCondition:
+		return e.evalCompare(c.Field, c.Value, func(cmp int) bool { return cmp > 0 })
+	case *filter.GreaterThanOrEqualCondition:
+		return e.evalCompare(c.Field, c.Value, func(cmp int) bool { return cmp >= 0 })
+	case *filter.LowerThanCondition:
+		return e.evalCompare(c.Field, c.Value, func(cmp int) bool { return cmp < 0 })
+	case *filter.LowerThanOrEqualCondition:
+		return e.evalCompare(c.Field, c.Value, func(cmp int) bool { return cmp <= 0 })
+	case *filter.ContainsCondition:
+		if _, ok := e.options.FullTextSearch[c.Field]; ok {
+			return e.evalSearch(c.Field, c.Value)
+		}
+		return e.evalString(c.Field, c.Value, strings.Contains)
+	case *StartsWithCondition:
+		return e.evalString(c.Field, c.Value, strings.HasPrefix)
+	case *EndsWithCondition:
+		return e.evalString(c.Field, c.Value, strings.HasSuffix)
+	case *SearchCondition:
+		return e.evalSearch(c.Field, c.Query)
+	case *filter.RegexCondition:
+		return e.evalRegex(c.Field, c.Expression, false)
+	case *filter.NotRegexCondition:
+		return e.evalRegex(c.Field, c.Expression, true)
+	case *filter.ArrayContainsCondition:
+		value, err := e.coerce(c.Field, c.Value)
+		if err != nil {
+			return triUnknown, err
+		}
+		if value == nil {
+			return triUnknown, fmt.Errorf("value cannot be nil")
+		}
+		return e.evalArray(c.Field, func(elements []any) tristate { return containsValue(elements, value) })
+	case *filter.ArrayContainsArrayCondition:
+		values, ok, err := e.arrayValues(c.Field, c.Value)
+		if err != nil || !ok {
+			return triFalse, err
+		}
+		return e.evalArray(c.Field, func(elements []any) tristate { return triOf(containsAll(elements, values)) })
+	case *filter.ArrayIsContainedCondition:
+		values, ok, err := e.arrayValues(c.Field, c.Value)
+		if err != nil || !ok {
+			return triFalse, err
+		}
+		return e.evalArray(c.Field, func(elements []any) tristate { return triOf(containsAll(values, elements)) })
+	case *filter.OverlapsCondition:
+		return e.evalOverlaps(c.Field, c.Value)
+	case *filter.ArraysOverlapCondition:
+		return e.evalOverlaps(c.Field, c.Value)
+	case *RangeContainsCondition:
+		return e.evalRange(c.Field, c.Value)
+	}
+	if condition == nil {
+		return triUnknown, fmt.Errorf("condition is nil")
+	}
+	return triUnknown, fmt.Errorf("%w: %s", ErrUnknownCondition, condition.Type())
+}
+
+// evalConjunction evaluates AND or OR: the dominant value decides, otherwise unknown beats its opposite.
+func (e *evaluator) evalConjunction(conditions []filter.Condition, dominant tristate) (tristate, error) {
+	result := dominant.not()
+	for _, c := range conditions {
+		r, err := e.eval(c)
+		if err != nil {
+			return triUnknown, err
+		}
+		if r == dominant {
+			return dominant, nil
+		}
+		if r == triUnknown {
+			result = triUnknown
+		}
+	}
+	return result, nil
+}
+
+// coerce coerces the value compared with the field like ApplyFilter, see WithFieldType.
+func (e *evaluator) coerce(field string, value any) (any, error) {
+	coerced, err := coerceFieldValue(e.options, field, value)
+	if err != nil {
+		return nil, fmt.Errorf("%w for field %s: %w", ErrInvalidValue, field, err)
+	}
+	return coerced, nil
+}
+
+// arrayValues coerces the value of an array condition and returns its elements.
+func (e *evaluator) arrayValues(field string, value any) ([]any, bool, error) {
+	value, err := e.coerce(field, value)
+	if err != nil {
+		return nil, false, err
+	}
+	values, ok := arrayValues(value)
+	return values, ok, nil
+}
+
+// evalField evaluates f with the value of the field, which is coerced with its FieldType like the compared values
+// to be comparable with them, e.g. UUIDs stored as byte arrays.
+func (e *evaluator) evalField(field string, f func(v any) (tristate, error)) (tristate, error) {
+	v, err := e.accessor(field)
+	if err != nil {
+		return triUnknown, err
+	}
+	if coerced, err := coerceFieldValue(e.options, field, v); err == nil {
+		v = coerced
+	}
+	return f(normalizeValue(v))
+}
+
+// evalEquals implements sq.Eq: NULL values are compared with IS NULL and lists with IN.
+func (e *evaluator) evalEquals(field string, v any, value any) (tristate, error) {
+	value, err := e.coerce(field, value)
+	if err != nil {
+		return triUnknown, err
+	}
+	if value == nil {
+		return triOf(isNull(v)), nil
+	}
+	// An empty list is rendered as a constant false, which is false for NULL values too.
+	values, _ := arrayValues(value)
+	if isListType(value) && len(values) == 0 {
+		return triFalse, nil
+	}
+	if isNull(v) {
+		return triUnknown, nil
+	}
+	caseInsensitive := e.options.CaseSensitivity[field] == CaseInsensitive
+	if caseInsensitive {
+		v, value = lowerValue(v), lowerValue(value)
+	}
+	if isListType(value) {
+		values, _ = arrayValues(value)
+		return containsValue(values, v), nil
+	}
+	return triOf(equalValues(v, normalizeValue(value))), nil
+}
+
+func (e *evaluator) evalCompare(field string, value any, accept func(cmp int) bool) (tristate, error) {
+	value, err := e.coerce(field, value)
+	if err != nil {
+		return triUnknown, err
+	}
+	if value == nil {
+		return triUnknown, fmt.Errorf("cannot use null with less than or greater than operators")
+	}
+	if isListType(value) {
+		return triUnknown, fmt.Errorf("cannot use array or slice with less than or greater than operators")
+	}
+	return e.evalField(field, func(v any) (tristate, error) {
+		if isNull(v) {
+			return triUnknown, nil
+		}
+		cmp, err := compareValues(v, normalizeValue(value))
+		if err != nil {
+			return triUnknown, err
+		}
+		return triOf(accept(cmp)), nil
+	})
+}
+
+func (e *evaluator) evalString(field string, value string, match func(s string, value string) bool) (tristate, error) {
+	return e.evalField(field, func(v any) (tristate, error) {
+		if isNull(v) {
+			return triUnknown, nil
+		}
+		s, ok := v.(string)
+		if !ok {
+			return triUnknown, fmt.Errorf("field %s is no string but %T", field, v)
+		}
+		if e.options.CaseSensitivity[field] != CaseSensitive {
+			s, value = strings.ToLower(s), strings.ToLower(value)
+		}
+		return triOf(match(s, value)), nil
+	})
+}
+
+// evalSearch approximates a full-text search by matching all words of the query case-insensitively,
+// without the stemming and stop words of the database.
+func (e *evaluator) evalSearch(field string, query string) (tristate, error) {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return triTrue, nil
+	}
+	return e.evalField(field, func(v any) (tristate, error) {
+		if isNull(v) {
+			return triUnknown, nil
+		}
+		s, ok := v.(string)
+		if !ok {
+			return triUnknown, fmt.Errorf("field %s is no string but %T", field, v)
+		}
+		s = strings.ToLower(s)
+		for _, word := range words {
+			if !strings.Contains(s, word) {
+				return triFalse, nil
+			}
+		}
+		return triTrue, nil
+	})
+}
+
+func (e *evaluator) evalRegex(field string, expression string, negate bool) (tristate, error) {
+	re, err := regexp.Compile(expression)
+	if err != nil {
+		return triUnknown, err
+	}
+	return e.evalField(field, func(v any) (tristate, error) {
+		if isNull(v) {
+			return triUnknown, nil
+		}
+		s, ok := v.(string)
+		if !ok {
+			return triUnknown, fmt.Errorf("field %s is no string but %T", field, v)
+		}
+		return triOf(re.MatchString(s) != negate), nil
+	})
+}
+
+func (e *evaluator) evalArray(field string, f func(elements []any) tristate) (tristate, error) {
+	return e.evalField(field, func(v any) (tristate, error) {
+		if isNull(v) {
+			return triUnknown, nil
+		}
+		elements, ok := arrayValues(v)
+		if !ok && !isListType(v) {
+			return triUnknown, fmt.Errorf("field %s is no array but %T", field, v)
+		}
+		return f(elements), nil
+	})
+}
+
+func (e *evaluator) evalOverlaps(field string, value any) (tristate, error) {
+	if _, ok := rangeValue(value); ok {
+		return e.evalRange(field, value)
+	}
+	values, ok, err := e.arrayValues(field, value)
+	if err != nil || !ok {
+		return triFalse, err
+	}
+	return e.evalArray(field, func(elements []any) tristate {
+		for _, v := range values {
+			if containsValue(elements, v) == triTrue {
+				return triTrue
+			}
+		}
+		return triFalse
+	})
+}
+
+// evalRange evaluates the overlap with a Range or the containment of a value in the range column or period.
+func (e *evaluator) evalRange(field string, value any) (tristate, error) {
+	value, err := e.coerce(field, value)
+	if err != nil {
+		return triUnknown, err
+	}
+	if value == nil {
+		return triUnknown, fmt.Errorf("value cannot be nil")
+	}
+	if period, ok := e.options.Periods[field]; ok {
+		return e.eval(periodConditions(period, value))
+	}
+	return e.evalField(field, func(v any) (tristate, error) {
+		if isNull(v) {
+			return triUnknown, nil
+		}
+		column, ok := rangeValue(v)
+		if !ok {
+			return triUnknown, fmt.Errorf("field %s is no range but %T", field, v)
+		}
+		var matches bool
+		if r, ok := rangeValue(value); ok {
+			matches, err = column.overlaps(r)
+		} else {
+			matches, err = column.contains(value)
+		}
+		return triOf(matches), err
+	})
+}
+
+// arrayValues returns the normalized elements of a list or a single value as list.
+// ok is false for nil values and empty lists, which the array conditions translate to a false condition.
+func arrayValues(value any) (values []any, ok bool) {
+	if value == nil {
+		return nil, false
+	}
+	if !isListType(value) {
+		return []any{normalizeValue(value)}, true
+	}
+	valVal := reflect.ValueOf(value)
+	for i := 0; i < valVal.Len(); i++ {
+		values = append(values, normalizeValue(valVal.Index(i).Interface()))
+	}
+	return values, len(values) > 0
+}
+
+// containsValue implements "v = ANY(elements)" and "v IN (elements)".
+func containsValue(elements []any, v any) tristate {
+	v = normalizeValue(v)
+	result := triFalse
+	for _, element := range elements {
+		if isNull(element) {
+			result = triUnknown
+			continue
+		}
+		if equalValues(element, v) {
+			return triTrue
+		}
+	}
+	return result
+}
+
+// containsAll implements "elements @> values", where NULL elements never match.
+func containsAll(elements []any, values []any) bool {
+	for _, v := range values {
+		if isNull(v) || containsValue(elements, v) != triTrue {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeValue dereferences pointers, resolves driver.Valuer and converts numbers to int64 or float64.
+func normalizeValue(v any) any {
+	if valuer, ok := v.(driver.Valuer); ok {
+		rv := reflect.ValueOf(v)
+		if rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return nil
+		}
+		value, err := valuer.Value()
+		if err == nil {
+			return normalizeValue(value)
+		}
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return nil
+		}
+		return normalizeValue(rv.Elem().Interface())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u := rv.Uint(); u <= 1<<63-1 {
+			return int64(u)
+		}
+		return float64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.String:
+		return rv.String()
+	case reflect.Bool:
+		return rv.Bool()
+	}
+	return v
+}
+
+func isNull(v any) bool {
+	return normalizeValue(v) == nil
+}
+
+func equalValues(a any, b any) bool {
+	cmp, err := compareValues(a, b)
+	if err == nil {
+		return cmp == 0
+	}
+	return reflect.DeepEqual(normalizeValue(a), normalizeValue(b))
+}
+
+// compareValues compares numbers, strings, booleans and times.
+func compareValues(a any, b any) (int, error) {
+	a, b = normalizeValue(a), normalizeValue(b)
+	switch av := a.(type) {
+	case int64:
+		switch bv := b.(type) {
+		case int64:
+			return compareOrdered(av, bv), nil
+		case float64:
+			return compareOrdered(float64(av), bv), nil
+		}
+	case float64:
+		switch bv := b.(type) {
+		case int64:
+			return compareOrdered(av, float64(bv)), nil
+		case float64:
+			return compareOrdered(av, bv), nil
+		}
+	case string:
+		if bv, ok := b.(string); ok {
+			return strings.Compare(av, bv), nil
+		}
+	case bool:
+		if bv, ok := b.(bool); ok {
+			return compareOrdered(boolToInt(av), boolToInt(bv)), nil
+		}
+	case time.Time:
+		if bv, ok := b.(time.Time); ok {
+			return av.Compare(bv), nil
+		}
+	}
+	return 0, fmt.Errorf("cannot compare %T with %T", a, b)
+}
+
+func compareOrdered[T int | int64 | float64](a T, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,241 @@
+package filtersquirrel
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/require"
+	"github.com/xafelium/filter"
+	"sort"
+	"testing"
+	"time"
+)
+
+// evaluateRows are evaluated in memory, the expected results follow the semantics of PostgreSQL for the SQL.
+var evaluateRows = []map[string]any{
+	{"id": 1, "name": "Alice", "status": "active", "age": 30, "score": 1.5, "nick": nil, "tags": []string{"a", "b"}, "valid": NewRange(1, 10)},
+	{"id": 2, "name": "bob", "status": nil, "age": nil, "score": 2.0, "nick": "b_50%", "tags": nil, "valid": nil},
+	{"id": 3, "name": "Carol", "status": "archived", "age": int64(45), "score": nil, "nick": "carol", "tags": []string{}, "valid": NewRange(5, nil)},
+	{"id": 4, "name": "dave", "status": "active", "age": uint8(18), "score": 3, "nick": "d", "tags": []any{"c", nil}, "valid": &Range{Lower: 10, Upper: 20, UpperInclusive: true}},
+}
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name        string
+		filter      filter.Condition
+		expectedSql string
+		expectedIDs []int
+	}{
+		{name: "empty where", filter: filter.Where(nil), expectedSql: "", expectedIDs: []int{1, 2, 3, 4}},
+		{name: "equals", filter: filter.Equals("status", "active"), expectedSql: "status = $1", expectedIDs: []int{1, 4}},
+		{name: "equals nil", filter: filter.Equals("status", nil), expectedSql: "status IS NULL", expectedIDs: []int{2}},
+		{name: "not equals excludes NULL", filter: filter.NotEquals("status", "active"), expectedSql: "status <> $1", expectedIDs: []int{3}},
+		{name: "not excludes NULL", filter: filter.Not(filter.Equals("status", "active")), expectedSql: "NOT (status = $1)", expectedIDs: []int{3}},
+		{name: "in", filter: filter.In("status", []string{"active", "archived"}), expectedSql: "status IN ($1,$2)", expectedIDs: []int{1, 3, 4}},
+		{name: "in with empty list", filter: filter.In("status", []string{}), expectedSql: "(1=0)"},
+		{name: "in with nil", filter: filter.In("status", nil), expectedSql: "(1=0)"},
+		{name: "in with scalar", filter: filter.In("status", "active"), expectedSql: "status IN ($1)", expectedIDs: []int{1, 4}},
+		{name: "not equals empty list", filter: filter.NotEquals("status", []string{}), expectedSql: "(1=1)", expectedIDs: []int{1, 2, 3, 4}},
+		{name: "is nil", filter: filter.IsNil("status"), expectedSql: "status IS NULL", expectedIDs: []int{2}},
+		{name: "not nil", filter: filter.NotNil("status"), expectedSql: "status IS NOT NULL", expectedIDs: []int{1, 3, 4}},
+		{name: "greater than", filter: filter.GreaterThan("age", 20), expectedSql: "age > $1", expectedIDs: []int{1, 3}},
+		{name: "greater than or equal", filter: filter.GreaterThanOrEqual("age", 30), expectedSql: "age >= $1", expectedIDs: []int{1, 3}},
+		{name: "lower than", filter: filter.LowerThan("age", 30), expectedSql: "age < $1", expectedIDs: []int{4}},
+		{name: "lower than or equal with float", filter: filter.LowerThanOrEqual("score", 2), expectedSql: "score <= $1", expectedIDs: []int{1, 2}},
+		{name: "contains ignores case", filter: filter.Contains("name", "O"), expectedSql: "name ILIKE $1", expectedIDs: []int{2, 3}},
+		{name: "contains matches wildcards literally", filter: filter.Contains("nick", "_50%"), expectedSql: "nick ILIKE $1", expectedIDs: []int{2}},
+		{name: "starts with", filter: StartsWith("name", "a"), expectedSql: "name ILIKE $1", expectedIDs: []int{1}},
+		{name: "ends with", filter: EndsWith("name", "E"), expectedSql: "name ILIKE $1", expectedIDs: []int{1, 4}},
+		{name: "search", filter: Search("name", "carol"), expectedSql: "to_tsvector(name) @@ websearch_to_tsquery($1)", expectedIDs: []int{3}},
+		{name: "regex", filter: filter.Regex("name", "^[A-Z]"), expectedSql: "name ~ $1", expectedIDs: []int{1, 3}},
+		{name: "not regex", filter: filter.NotRegex("name", "^[A-Z]"), expectedSql: "name !~ $1", expectedIDs: []int{2, 4}},
+		{name: "array contains", filter: filter.ArrayContains("tags", "a"), expectedSql: "tags = ANY ($1)", expectedIDs: []int{1}},
+		{name: "not array contains with NULL elements", filter: filter.Not(filter.ArrayContains("tags", "a")), expectedSql: "NOT (tags = ANY ($1))", expectedIDs: []int{3}},
+		{name: "array contains array", filter: filter.ArrayContainsArray("tags", []string{"a", "b"}), expectedSql: "tags @> ARRAY[$1,$2]", expectedIDs: []int{1}},
+		{name: "array is contained", filter: filter.ArrayIsContained("tags", []string{"a", "b", "c"}), expectedSql: "tags <@ ARRAY[$1,$2,$3]", expectedIDs: []int{1, 3}},
+		{name: "overlaps", filter: filter.Overlaps("tags", []string{"b", "c"}), expectedSql: "tags && ARRAY[$1,$2]", expectedIDs: []int{1, 4}},
+		{name: "overlaps with nil", filter: filter.Overlaps("tags", nil), expectedSql: "(1=0)"},
+		{name: "arrays overlap", filter: filter.ArraysOverlap("tags", []string{"x"}), expectedSql: "tags && ARRAY[$1]"},
+		{name: "range overlaps", filter: filter.Overlaps("valid", NewRange(8, 11)), expectedSql: "valid && int8range($1,$2,'[)')", expectedIDs: []int{1, 3}},
+		{name: "range contains", filter: RangeContains("valid", 10), expectedSql: "valid @> $1::bigint", expectedIDs: []int{3}},
+		{
+			name:        "and",
+			filter:      filter.And(filter.Equals("status", "active"), filter.GreaterThan("age", 20)),
+			expectedSql: "(status = $1 AND age > $2)",
+			expectedIDs: []int{1},
+		},
+		{
+			name:        "or",
+			filter:      filter.Or(filter.Equals("status", "archived"), filter.GreaterThan("age", 40)),
+			expectedSql: "(status = $1 OR age > $2)",
+			expectedIDs: []int{3},
+		},
+		{
+			name:        "group with unknown",
+			filter:      filter.Where(filter.Group(filter.Or(filter.IsNil("status"), filter.LowerThan("score", 2)))),
+			expectedSql: "(status IS NULL OR score < $1)",
+			expectedIDs: []int{1, 2},
+		},
+		{
+			name:        "not with unknown",
+			filter:      filter.Not(filter.Or(filter.Equals("status", "active"), filter.GreaterThan("age", 40))),
+			expectedSql: "NOT ((status = $1 OR age > $2))",
+		},
+	}
+
+	covered := make(map[string]bool)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			builder, _, err := ApplyFilter(sq.Select("id").From("x").PlaceholderFormat(sq.Dollar), test.filter)
+			require.NoError(t, err)
+			sql, _, err := builder.ToSql()
+			require.NoError(t, err)
+			expectedSql := "SELECT id FROM x"
+			if test.expectedSql != "" {
+				expectedSql += " WHERE " + test.expectedSql
+			}
+			require.Equal(t, expectedSql, sql)
+
+			var ids []int
+			for _, row := range evaluateRows {
+				matches, err := Evaluate(test.filter, MapAccessor(row))
+				require.NoError(t, err)
+				if matches {
+					ids = append(ids, row["id"].(int))
+				}
+			}
+			require.Equal(t, test.expectedIDs, ids)
+		})
+		_ = walkCondition(test.filter, "", func(c filter.Condition, path string) error {
+			covered[c.Type()] = true
+			return nil
+		})
+	}
+
+	var coveredTypes []string
+	for conditionType := range covered {
+		coveredTypes = append(coveredTypes, conditionType)
+	}
+	sort.Strings(coveredTypes)
+	expected := append(filter.AllConditionTypes(), ConditionTypes()...)
+	sort.Strings(expected)
+	require.Equal(t, expected, coveredTypes)
+}
+
+func TestEvaluateWithOptions(t *testing.T) {
+	row := MapAccessor(map[string]any{"email": "John@Example.com", "code": "AbC"})
+
+	matches, err := Evaluate(filter.Equals("email", "john@example.com"), row)
+	require.NoError(t, err)
+	require.False(t, matches)
+
+	matches, err = Evaluate(filter.Equals("email", "john@example.com"), row, WithCaseSensitivity(CaseInsensitive, "email"))
+	require.NoError(t, err)
+	require.True(t, matches)
+
+	matches, err = Evaluate(filter.Equals("email", caseTestEmail("JOHN@example.com")), row, WithCaseSensitivity(CaseInsensitive, "email"))
+	require.NoError(t, err)
+	require.True(t, matches)
+
+	email := "JOHN@example.com"
+	matches, err = Evaluate(filter.In("email", []*string{&email}), row, WithCaseSensitivity(CaseInsensitive, "email"))
+	require.NoError(t, err)
+	require.True(t, matches)
+
+	matches, err = Evaluate(filter.Contains("code", "abc"), row, WithCaseSensitivity(CaseSensitive, "code"))
+	require.NoError(t, err)
+	require.False(t, matches)
+
+	row = MapAccessor(map[string]any{"age": 42, "ids": []int{1, 2}})
+	matches, err = Evaluate(filter.And(
+		filter.GreaterThan("age", "41"),
+		filter.ArrayContains("ids", "2"),
+	), row, WithFieldType(IntType, "age", "ids"))
+	require.NoError(t, err)
+	require.True(t, matches)
+
+	_, err = Evaluate(filter.Equals("age", "old"), row, WithFieldType(IntType, "age"))
+	require.ErrorContains(t, err, `invalid value for field age: "old" is no integer`)
+}
+
+func TestEvaluateWithNullAwareNegation(t *testing.T) {
+	tests := []struct {
+		name        string
+		filter      filter.Condition
+		expectedIDs []int
+	}{
+		{name: "not equals", filter: filter.NotEquals("status", "active"), expectedIDs: []int{2, 3}},
+		{name: "not equals list", filter: filter.NotEquals("status", []string{"active"}), expectedIDs: []int{2, 3}},
+		{name: "not equals nil", filter: filter.NotEquals("status", nil), expectedIDs: []int{1, 3, 4}},
+		{name: "not", filter: filter.Not(filter.Equals("status", "active")), expectedIDs: []int{2, 3}},
+		{
+			name:        "not with unknown",
+			filter:      filter.Not(filter.Or(filter.Equals("status", "active"), filter.GreaterThan("age", 40))),
+			expectedIDs: []int{2},
+		},
+		{name: "not array contains with NULL elements", filter: filter.Not(filter.ArrayContains("tags", "a")), expectedIDs: []int{2, 3, 4}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var ids []int
+			for _, row := range evaluateRows {
+				matches, err := Evaluate(test.filter, MapAccessor(row), WithNullAwareNegation(true))
+				require.NoError(t, err)
+				if matches {
+					ids = append(ids, row["id"].(int))
+				}
+			}
+			require.Equal(t, test.expectedIDs, ids)
+		})
+	}
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	row := MapAccessor(map[string]any{"age": 1, "name": 2})
+
+	_, err := Evaluate(filter.Equals("unknown", 1), row)
+	require.ErrorContains(t, err, "unknown field: unknown")
+
+	_, err = Evaluate(filter.GreaterThan("age", nil), row)
+	require.ErrorContains(t, err, "cannot use null with less than or greater than operators")
+
+	_, err = Evaluate(filter.GreaterThan("age", "x"), row)
+	require.ErrorContains(t, err, "cannot compare int64 with string")
+
+	_, err = Evaluate(filter.Contains("name", "x"), row)
+	require.ErrorContains(t, err, "field name is no string but int")
+
+	_, err = Evaluate(filter.Or(filter.Equals("age", 1)), row)
+	require.ErrorContains(t, err, "OR condition must have at least two conditions")
+
+	_, err = Evaluate(&withinRadiusCondition{}, row)
+	require.ErrorContains(t, err, "unknown condition: WithinRadiusCondition")
+}
+
+func TestEvaluateWithStructAccessor(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	user := testUser{
+		testAuditable: testAuditable{CreatedAt: created},
+		Email:         "x@y.z",
+		Address:       &testAddress{City: "Berlin"},
+	}
+	accessor, err := StructAccessor(&user)
+	require.NoError(t, err)
+
+	matches, err := Evaluate(filter.Where(filter.And(
+		filter.Equals("address.city", "Berlin"),
+		filter.GreaterThan("created_at", created.Add(-time.Hour)),
+		filter.IsNil("updated_at"),
+		filter.Contains("email", "Y.Z"),
+	)), accessor)
+	require.NoError(t, err)
+	require.True(t, matches)
+
+	user.Address = nil
+	matches, err = Evaluate(filter.Not(filter.Equals("address.city", "Berlin")), accessor)
+	require.NoError(t, err)
+	require.False(t, matches)
+
+	_, err = Evaluate(filter.Equals("password", "x"), accessor)
+	require.ErrorContains(t, err, "unknown field: password")
+}
@@ -0,0 +1,325 @@
+package filtersquirrel
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"github.com/xafelium/filter"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldType coerces the values compared with a field to the type of its column, e.g. the strings of query
+// parameters to numbers.
+type FieldType struct {
+	// Coerce coerces a value. It returns an error describing invalid values.
+	Coerce func(value any) (any, error)
+	// SQLType is the PostgreSQL type of the column, e.g. uuid. It is derived from the Go type of the coerced values
+	// if empty.
+	SQLType string
+}
+
+var (
+	// StringType converts numbers and booleans to strings.
+	StringType = FieldType{Coerce: coerceString}
+	// IntType parses integers into int64.
+	IntType = FieldType{Coerce: coerceInt}
+	// FloatType parses numbers into float64.
+	FloatType = FieldType{Coerce: coerceFloat}
+	// BoolType parses booleans as accepted by strconv.ParseBool.
+	BoolType = FieldType{Coerce: coerceBool}
+	// TimeType parses RFC 3339 timestamps and dates. Times without time zone are in UTC.
+	TimeType = FieldType{Coerce: coerceTime, SQLType: "timestamptz"}
+	// UUIDType validates UUIDs and converts them to their lower case string representation.
+	UUIDType = FieldType{Coerce: coerceUUID, SQLType: "uuid"}
+	// DecimalType validates decimal numbers and keeps strings as they are to preserve their precision.
+	DecimalType = FieldType{Coerce: coerceDecimal, SQLType: "numeric"}
+)
+
+// EnumType accepts only the values, compared case-sensitively.
+func EnumType(values ...string) FieldType {
+	return FieldType{Coerce: func(value any) (any, error) {
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%v is no string", value)
+		}
+		for _, v := range values {
+			if s == v {
+				return s, nil
+			}
+		}
+		return nil, fmt.Errorf("%q is not one of %s", s, strings.Join(values, ", "))
+	}}
+}
+
+// fieldTypeNames are the names of the field types in the type option of the filter tag.
+var fieldTypeNames = map[string]FieldType{
+	"string":  StringType,
+	"int":     IntType,
+	"float":   FloatType,
+	"bool":    BoolType,
+	"time":    TimeType,
+	"uuid":    UUIDType,
+	"decimal": DecimalType,
+}
+
+// coercedConditionTypes compare the field with values of its type, unlike the text patterns of e.g. Contains.
+var coercedConditionTypes = map[string]bool{
+	filter.EqualsConditionType:             true,
+	filter.NotEqualsConditionType:          true,
+	filter.InConditionType:                 true,
+	filter.GreaterThanConditionType:        true,
+	filter.GreaterThanOrEqualConditionType: true,
+	filter.LowerThanConditionType:          true,
+	filter.LowerThanOrEqualConditionType:   true,
+	filter.ArrayContainsConditionType:      true,
+	filter.ArrayContainsArrayConditionType: true,
+	filter.ArrayIsContainedConditionType:   true,
+	filter.OverlapsConditionType:           true,
+	filter.ArraysOverlapConditionType:      true,
+	RangeContainsConditionType:             true,
+}
+
+// coerceFieldValue coerces the value, the elements of a list value or the bounds of a Range with the FieldType of the
+// domain field. NULL values are kept.
+func coerceFieldValue(options *Options, field string, value any) (any, error) {
+	fieldType, ok := fieldOption(options, options.FieldTypes, field)
+	if !ok || value == nil {
+		return value, nil
+	}
+	if isListValue(value) {
+		valVal := reflect.ValueOf(value)
+		coerced := make([]any, valVal.Len())
+		for i := range coerced {
+			v, err := coerceFieldValue(options, field, valVal.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			coerced[i] = v
+		}
+		return typedList(coerced), nil
+	}
+	if r, ok := value.(Range); ok {
+		return r.coerce(func(v any) (any, error) { return coerceFieldValue(options, field, v) })
+	}
+	valVal := reflect.ValueOf(value)
+	if valVal.Kind() == reflect.Pointer {
+		if valVal.IsNil() {
+			return nil, nil
+		}
+		return coerceFieldValue(options, field, valVal.Elem().Interface())
+	}
+	return fieldType.Coerce(value)
+}
+
+// isListValue reports whether the elements of the value are coerced one by one. Byte arrays like UUIDs and values
+// implementing fmt.Stringer or driver.Valuer are single values.
+func isListValue(value any) bool {
+	switch value.(type) {
+	case fmt.Stringer, driver.Valuer:
+		return false
+	}
+	if t := reflect.TypeOf(value); t != nil && t.Kind() == reflect.Array && t.Elem().Kind() == reflect.Uint8 {
+		return false
+	}
+	return isListType(value)
+}
+
+// coerceValue coerces the value compared with the domain field, see WithFieldType.
+func (ctx *ApplyContext) coerceValue(field string, value any) (any, error) {
+	coerced, err := coerceFieldValue(ctx.options, field, value)
+	if err != nil {
+		return nil, fmt.Errorf("%w for field %s: %w", ErrInvalidValue, field, err)
+	}
+	return coerced, nil
+}
+
+func coerceString(value any) (any, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case fmt.Stringer:
+		return v.String(), nil
+	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return fmt.Sprint(value), nil
+	}
+	return nil, fmt.Errorf("%v is no string", value)
+}
+
+func coerceInt(value any) (any, error) {
+	switch v := value.(type) {
+	case string:
+		i, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is no integer", v)
+		}
+		return i, nil
+	case json.Number:
+		return coerceInt(v.String())
+	}
+	valVal := reflect.ValueOf(value)
+	switch valVal.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return valVal.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if valVal.Uint() > math.MaxInt64 {
+			return nil, fmt.Errorf("%v is out of range", value)
+		}
+		return int64(valVal.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		f := valVal.Float()
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			return nil, fmt.Errorf("%v is no integer", value)
+		}
+		return int64(f), nil
+	}
+	return nil, fmt.Errorf("%v is no integer", value)
+}
+
+func coerceFloat(value any) (any, error) {
+	switch v := value.(type) {
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("%q is no number", v)
+		}
+		return f, nil
+	case json.Number:
+		return coerceFloat(v.String())
+	}
+	valVal := reflect.ValueOf(value)
+	switch valVal.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(valVal.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(valVal.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return valVal.Float(), nil
+	}
+	return nil, fmt.Errorf("%v is no number", value)
+}
+
+func coerceBool(value any) (any, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			return nil, fmt.Errorf("%q is no boolean", v)
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("%v is no boolean", value)
+}
+
+// timeLayouts are the layouts accepted by TimeType.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+func coerceTime(value any) (any, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, strings.TrimSpace(v)); err == nil {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("%q is no time", v)
+	}
+	return nil, fmt.Errorf("%v is no time", value)
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{4}-?[0-9a-fA-F]{12}$`)
+
+func coerceUUID(value any) (any, error) {
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = strings.Trim(strings.TrimSpace(v), "{}")
+	case [16]byte:
+		s = fmt.Sprintf("%x", v[:])
+	case fmt.Stringer:
+		s = v.String()
+	default:
+		valVal := reflect.ValueOf(value)
+		if valVal.Kind() != reflect.Array || valVal.Len() != 16 || valVal.Type().Elem().Kind() != reflect.Uint8 {
+			return nil, fmt.Errorf("%v is no UUID", value)
+		}
+		b := make([]byte, 16)
+		reflect.Copy(reflect.ValueOf(b), valVal)
+		s = fmt.Sprintf("%x", b)
+	}
+	if !uuidPattern.MatchString(s) {
+		return nil, fmt.Errorf("%q is no UUID", s)
+	}
+	s = strings.ToLower(strings.ReplaceAll(s, "-", ""))
+	return s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:], nil
+}
+
+var decimalPattern = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)([eE][+-]?\d+)?$`)
+
+func coerceDecimal(value any) (any, error) {
+	switch v := value.(type) {
+	case string:
+		s := strings.TrimSpace(v)
+		if !decimalPattern.MatchString(s) {
+			return nil, fmt.Errorf("%q is no decimal number", v)
+		}
+		return s, nil
+	case json.Number:
+		return coerceDecimal(v.String())
+	}
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return value, nil
+	case reflect.Float32, reflect.Float64:
+		return coerceFloat(value)
+	}
+	return nil, fmt.Errorf("%v is no decimal number", value)
+}
+
+// fieldTypeOf derives the FieldType from the Go type of a struct field or the elements of a slice.
+func fieldTypeOf(t reflect.Type) (FieldType, bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return TimeType, true
+	}
+	if t.Kind() == reflect.Array && t.Len() == 16 && t.Elem().Kind() == reflect.Uint8 {
+		return UUIDType, true
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return FieldType{}, false
+		}
+		return fieldTypeOf(t.Elem())
+	case reflect.String:
+		return StringType, true
+	case reflect.Bool:
+		return BoolType, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return IntType, true
+	case reflect.Float32, reflect.Float64:
+		return FloatType, true
+	}
+	return FieldType{}, false
+}
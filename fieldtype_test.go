@@ -0,0 +1,188 @@
+package filtersquirrel
+
+import (
+	"encoding/json"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/require"
+	"github.com/xafelium/filter"
+	"testing"
+	"time"
+)
+
+type testUUID [16]byte
+
+func TestFieldTypes(t *testing.T) {
+	tests := []struct {
+		name        string
+		fieldType   FieldType
+		value       any
+		expected    any
+		errContains string
+	}{
+		{name: "string", fieldType: StringType, value: "a", expected: "a"},
+		{name: "string from number", fieldType: StringType, value: 42, expected: "42"},
+		{name: "string from struct", fieldType: StringType, value: struct{}{}, errContains: "{} is no string"},
+		{name: "int", fieldType: IntType, value: " 42", expected: int64(42)},
+		{name: "int from uint", fieldType: IntType, value: uint8(42), expected: int64(42)},
+		{name: "int from float", fieldType: IntType, value: 42.0, expected: int64(42)},
+		{name: "int from json number", fieldType: IntType, value: json.Number("42"), expected: int64(42)},
+		{name: "int with fraction", fieldType: IntType, value: 4.2, errContains: "4.2 is no integer"},
+		{name: "invalid int", fieldType: IntType, value: "4x", errContains: `"4x" is no integer`},
+		{name: "float", fieldType: FloatType, value: "4.5", expected: 4.5},
+		{name: "float from int", fieldType: FloatType, value: 4, expected: float64(4)},
+		{name: "invalid float", fieldType: FloatType, value: "NaN", errContains: `"NaN" is no number`},
+		{name: "bool", fieldType: BoolType, value: "t", expected: true},
+		{name: "invalid bool", fieldType: BoolType, value: "yes", errContains: `"yes" is no boolean`},
+		{name: "time", fieldType: TimeType, value: "2024-01-02T03:04:05+01:00", expected: time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("", 3600))},
+		{name: "date", fieldType: TimeType, value: "2024-01-02", expected: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{name: "invalid time", fieldType: TimeType, value: "yesterday", errContains: `"yesterday" is no time`},
+		{name: "uuid", fieldType: UUIDType, value: "{A0EEBC99-9C0B-4EF8-BB6D-6BB9BD380A11}", expected: "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"},
+		{name: "uuid without hyphens", fieldType: UUIDType, value: "a0eebc999c0b4ef8bb6d6bb9bd380a11", expected: "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"},
+		{name: "uuid from array", fieldType: UUIDType, value: [16]byte{0xa0, 0xee, 0xbc, 0x99, 0x9c, 0x0b, 0x4e, 0xf8, 0xbb, 0x6d, 0x6b, 0xb9, 0xbd, 0x38, 0x0a, 0x11}, expected: "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"},
+		{name: "invalid uuid", fieldType: UUIDType, value: "a0eebc99", errContains: `"a0eebc99" is no UUID`},
+		{name: "decimal", fieldType: DecimalType, value: "-12.50", expected: "-12.50"},
+		{name: "decimal from int", fieldType: DecimalType, value: 12, expected: 12},
+		{name: "invalid decimal", fieldType: DecimalType, value: "12,5", errContains: `"12,5" is no decimal number`},
+		{name: "enum", fieldType: EnumType("active", "archived"), value: "active", expected: "active"},
+		{name: "invalid enum", fieldType: EnumType("active", "archived"), value: "Active", errContains: `"Active" is not one of active, archived`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := test.fieldType.Coerce(test.value)
+			if test.errContains != "" {
+				require.ErrorContains(t, err, test.errContains)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestApplyFilterWithFieldTypes(t *testing.T) {
+	opts := []Option{
+		WithFieldType(IntType, "age", "ids"),
+		WithFieldType(TimeType, "created_at"),
+		WithFieldType(EnumType("active", "archived"), "status"),
+		WithFieldType(BoolType, "attributes.active"),
+		WithJSONField("attributes"),
+	}
+
+	tests := []struct {
+		name         string
+		filter       filter.Condition
+		expectedSql  string
+		expectedArgs []any
+		errContains  string
+	}{
+		{
+			name: "coerced values",
+			filter: filter.Where(filter.And(
+				filter.GreaterThan("age", "42"),
+				filter.LowerThan("created_at", "2024-01-01"),
+				filter.In("status", []string{"active", "archived"}),
+				filter.Equals("attributes.active", "true"),
+				filter.IsNil("age"),
+				filter.Contains("status", "act"),
+			)),
+			expectedSql: "SELECT * FROM users WHERE (age > $1 AND created_at < $2 AND status IN ($3,$4) AND " +
+				"(attributes->>'active')::boolean = $5 AND age IS NULL AND status ILIKE $6)",
+			expectedArgs: []any{int64(42), time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), "active", "archived", true, "%act%"},
+		},
+		{
+			name:         "array values",
+			filter:       filter.Where(filter.ArrayContainsArray("ids", []string{"1", "2"})),
+			expectedSql:  "SELECT * FROM users WHERE ids @> ARRAY[$1,$2]",
+			expectedArgs: []any{int64(1), int64(2)},
+		},
+		{
+			name: "invalid values",
+			filter: filter.Where(filter.Or(
+				filter.Equals("age", "old"),
+				filter.Not(filter.In("status", []string{"active", "deleted"})),
+			)),
+			errContains: `invalid filter: where.or[0]: invalid value for field age: "old" is no integer; ` +
+				`where.or[1].not: invalid value for field status: "deleted" is not one of active, archived`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			builder, _, err := ApplyFilter(sq.Select("*").From("users").PlaceholderFormat(sq.Dollar), test.filter, opts...)
+
+			if test.errContains != "" {
+				var validationErr *ValidationError
+				require.ErrorAs(t, err, &validationErr)
+				require.ErrorContains(t, err, test.errContains)
+				return
+			}
+			require.NoError(t, err)
+			sql, args, err := builder.ToSql()
+			require.NoError(t, err)
+			require.Equal(t, test.expectedSql, sql)
+			require.Equal(t, test.expectedArgs, args)
+		})
+	}
+}
+
+func TestStructMapperFieldTypes(t *testing.T) {
+	m, err := NewStructMapper(struct {
+		ID      testUUID  `filter:"id"`
+		Age     *int      `filter:"age"`
+		Price   string    `filter:"price,type=decimal"`
+		Status  string    `filter:"status,enum=active|archived"`
+		Tags    []string  `filter:"tags"`
+		Created time.Time `filter:"created"`
+		Data    []byte    `filter:"data"`
+	}{})
+	require.NoError(t, err)
+
+	types := m.FieldTypes()
+	require.Len(t, types, 6)
+	require.NotContains(t, types, "data")
+
+	err = Validate(filter.Where(filter.And(
+		filter.Equals("age", "42"),
+		filter.In("status", []string{"deleted"}),
+		filter.Equals("price", "1e3"),
+		filter.Equals("id", "a0eebc99"),
+	)), WithFieldTypes(types))
+	require.EqualError(t, err, `invalid filter: `+
+		`where.and[1]: invalid value for field status: "deleted" is not one of active, archived; `+
+		`where.and[3]: invalid value for field id: "a0eebc99" is no UUID`)
+
+	_, err = NewStructMapper(struct {
+		A string `filter:"a,type=money"`
+	}{})
+	require.ErrorContains(t, err, "field a: unknown field type: money")
+
+	_, err = NewStructMapper(struct {
+		A string `filter:"a,sortable"`
+	}{})
+	require.ErrorContains(t, err, "field a: unknown filter tag option: sortable")
+}
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestApplyFilterWithUUIDArrays(t *testing.T) {
+	type user struct {
+		ID testUUID `filter:"id"`
+	}
+	m, err := NewStructMapper(user{})
+	require.NoError(t, err)
+	id := testUUID{0xa0, 0xee, 0xbc, 0x99, 0x9c, 0x0b, 0x4e, 0xf8, 0xbb, 0x6d, 0x6b, 0xb9, 0xbd, 0x38, 0x0a, 0x11}
+
+	builder, _, err := ApplyFilter(sq.Select("*").From("users").PlaceholderFormat(sq.Dollar),
+		filter.Where(filter.Or(filter.Equals("id", id), filter.In("id", []testUUID{id}))),
+		WithMapperFunc(m.Map), WithFieldTypes(m.FieldTypes()))
+	require.NoError(t, err)
+	sql, args, err := builder.ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT * FROM users WHERE (id = $1 OR id IN ($2))", sql)
+	require.Equal(t, []any{"a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11", "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"}, args)
+
+	matches, err := Evaluate(filter.Equals("id", id), m.Accessor(user{ID: id}), WithFieldTypes(m.FieldTypes()))
+	require.NoError(t, err)
+	require.True(t, matches)
+}
@@ -0,0 +1,78 @@
+package filtersquirrel
+
+import (
+	"fmt"
+	sq "github.com/Masterminds/squirrel"
+	"reflect"
+)
+
+// in renders the membership of the column in a list of values, which is used by In conditions and by Equals and
+// NotEquals conditions with list values:
+//
+//   - a nil or empty list matches no rows, negated it matches all rows, including NULL values
+//   - a scalar value is a list of one value
+//   - NULL values in the list never match, like in SQL
+//
+// Lists with at least ArrayParameterThreshold values are bound as a single array parameter if the dialect
+// supports it, e.g. column = ANY($1), which keeps the statement the same for lists of different length.
+// The number of values is limited by MaxInValues.
+func (ctx *ApplyContext) in(column string, value any, negate bool) (sq.Sqlizer, error) {
+	list := listValue(value)
+	if err := ctx.checkInValues(list); err != nil {
+		return nil, err
+	}
+	n := reflect.ValueOf(list).Len()
+	switch {
+	case n == 0 && negate:
+		return sq.Expr(sqlTrue), nil
+	case n == 0:
+		return sq.Expr(sqlFalse), nil
+	}
+	threshold := ctx.options.ArrayParameterThreshold
+	if threshold <= 0 || n < threshold || !ctx.Dialect().SupportsArrayParameters() {
+		if negate {
+			return sq.NotEq{column: list}, nil
+		}
+		return sq.Eq{column: list}, nil
+	}
+	var arg any = list
+	if ctx.options.ArrayParameterFunc != nil {
+		arg = ctx.options.ArrayParameterFunc(list)
+	}
+	if negate {
+		return sq.Expr(fmt.Sprintf("%s <> ALL (?)", column), arg), nil
+	}
+	return sq.Expr(fmt.Sprintf("%s = ANY (?)", column), arg), nil
+}
+
+// listValue returns the list value as it is, a scalar value as a list of one value and nil as an empty list.
+func listValue(value any) any {
+	if value == nil {
+		return []any{}
+	}
+	if isListType(value) {
+		return value
+	}
+	list := reflect.MakeSlice(reflect.SliceOf(reflect.TypeOf(value)), 1, 1)
+	list.Index(0).Set(reflect.ValueOf(value))
+	return list.Interface()
+}
+
+// typedList returns the values as a slice of their type if they all have the same one, so drivers can bind it
+// as an array. Otherwise, it returns the values as they are.
+func typedList(values []any) any {
+	if len(values) == 0 || values[0] == nil {
+		return values
+	}
+	t := reflect.TypeOf(values[0])
+	for _, v := range values {
+		if reflect.TypeOf(v) != t {
+			return values
+		}
+	}
+	list := reflect.MakeSlice(reflect.SliceOf(t), len(values), len(values))
+	for i, v := range values {
+		list.Index(i).Set(reflect.ValueOf(v))
+	}
+	return list.Interface()
+}
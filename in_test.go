@@ -0,0 +1,130 @@
+package filtersquirrel
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/require"
+	"github.com/xafelium/filter"
+	"testing"
+)
+
+type intArray []int
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestApplyFilterWithInValues(t *testing.T) {
+	tests := []struct {
+		name         string
+		dialect      Dialect
+		opts         []Option
+		filter       filter.Condition
+		expectedSql  string
+		expectedArgs []any
+	}{
+		{
+			name:        "nil",
+			filter:      filter.Where(filter.In("id", nil)),
+			expectedSql: "SELECT * FROM users WHERE (1=0)",
+		},
+		{
+			name:        "empty list",
+			filter:      filter.Where(filter.In("id", []int{})),
+			expectedSql: "SELECT * FROM users WHERE (1=0)",
+		},
+		{
+			name:        "not in empty list",
+			filter:      filter.Where(filter.Not(filter.In("id", []int{}))),
+			expectedSql: "SELECT * FROM users WHERE NOT ((1=0))",
+		},
+		{
+			name:         "scalar",
+			filter:       filter.Where(filter.In("id", 1)),
+			expectedSql:  "SELECT * FROM users WHERE id IN ($1)",
+			expectedArgs: []any{1},
+		},
+		{
+			name:         "list",
+			filter:       filter.Where(filter.In("id", []int{1, 2})),
+			expectedSql:  "SELECT * FROM users WHERE id IN ($1,$2)",
+			expectedArgs: []any{1, 2},
+		},
+		{
+			name:         "equals list",
+			filter:       filter.Where(filter.Equals("id", []int{1, 2})),
+			expectedSql:  "SELECT * FROM users WHERE id IN ($1,$2)",
+			expectedArgs: []any{1, 2},
+		},
+		{
+			name:        "not equals empty list",
+			filter:      filter.Where(filter.NotEquals("id", []int{})),
+			expectedSql: "SELECT * FROM users WHERE (1=1)",
+		},
+		{
+			name:        "not equals empty list with null-aware negation",
+			opts:        []Option{WithNullAwareNegation(true)},
+			filter:      filter.Where(filter.NotEquals("id", []int{})),
+			expectedSql: "SELECT * FROM users WHERE ((1=1) OR id IS NULL)",
+		},
+		{
+			name:         "list below array parameter threshold",
+			opts:         []Option{WithArrayParameters(3, nil)},
+			filter:       filter.Where(filter.In("id", []int{1, 2})),
+			expectedSql:  "SELECT * FROM users WHERE id IN ($1,$2)",
+			expectedArgs: []any{1, 2},
+		},
+		{
+			name:         "array parameter",
+			opts:         []Option{WithArrayParameters(3, nil)},
+			filter:       filter.Where(filter.In("id", []int{1, 2, 3})),
+			expectedSql:  "SELECT * FROM users WHERE id = ANY ($1)",
+			expectedArgs: []any{[]int{1, 2, 3}},
+		},
+		{
+			name:         "not equals array parameter",
+			opts:         []Option{WithArrayParameters(1, nil)},
+			filter:       filter.Where(filter.NotEquals("id", []int{1, 2})),
+			expectedSql:  "SELECT * FROM users WHERE id <> ALL ($1)",
+			expectedArgs: []any{[]int{1, 2}},
+		},
+		{
+			name: "array parameter func",
+			opts: []Option{WithArrayParameters(1, func(list any) any {
+				return intArray(list.([]int))
+			})},
+			filter:       filter.Where(filter.In("id", []int{1, 2})),
+			expectedSql:  "SELECT * FROM users WHERE id = ANY ($1)",
+			expectedArgs: []any{intArray{1, 2}},
+		},
+		{
+			name:         "array parameter of coerced values",
+			opts:         []Option{WithArrayParameters(1, nil), WithFieldType(IntType, "id")},
+			filter:       filter.Where(filter.In("id", []string{"1", "2"})),
+			expectedSql:  "SELECT * FROM users WHERE id = ANY ($1)",
+			expectedArgs: []any{[]int64{1, 2}},
+		},
+		{
+			name:         "array parameters not supported",
+			dialect:      MySQL,
+			opts:         []Option{WithArrayParameters(1, nil)},
+			filter:       filter.Where(filter.In("id", []int{1, 2})),
+			expectedSql:  "SELECT * FROM users WHERE id IN (?,?)",
+			expectedArgs: []any{1, 2},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			builder := sq.Select("*").From("users")
+			dialect := test.dialect
+			if dialect == nil {
+				dialect = Postgres
+				builder = builder.PlaceholderFormat(sq.Dollar)
+			}
+			opts := append([]Option{WithDialect(dialect)}, test.opts...)
+			builder, _, err := ApplyFilter(builder, test.filter, opts...)
+			require.NoError(t, err)
+			sql, args, err := builder.ToSql()
+			require.NoError(t, err)
+			require.Equal(t, test.expectedSql, sql)
+			require.Equal(t, test.expectedArgs, args)
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package filtersquirrel
+
+import (
+	"fmt"
+	sq "github.com/Masterminds/squirrel"
+)
+
+// JoinType is the SQL keyword used to join a table.
+type JoinType string
+
+const (
+	InnerJoin JoinType = "JOIN"
+	LeftJoin  JoinType = "LEFT JOIN"
+)
+
+// Join describes how to join the table of an alias referenced by a filter.
+//
+//	Join{Alias: "a", Type: LeftJoin, Table: "addresses a", On: "a.id = u.address_id"}
+type Join struct {
+	// Alias is the table alias the fields are mapped to.
+	Alias string
+	// Type defaults to InnerJoin.
+	Type JoinType
+	// Table is the joined table including the alias, e.g. "addresses a".
+	Table string
+	// On is the join condition, e.g. "a.id = u.address_id".
+	On string
+	// Args are the arguments of the placeholders in On.
+	Args []any
+	// DependsOn lists the aliases which must be joined before this one.
+	DependsOn []string
+	// ToMany renders the conditions on this alias as EXISTS subqueries instead of joining the table,
+	// which avoids duplicate rows for one-to-many relations.
+	ToMany bool
+}
+
+func (j Join) joinType() JoinType {
+	if j.Type == "" {
+		return InnerJoin
+	}
+	return j.Type
+}
+
+// ToSql renders the join clause.
+func (j Join) ToSql() (string, []interface{}, error) {
+	return fmt.Sprintf("%s %s ON %s", j.joinType(), j.Table, j.On), j.Args, nil
+}
+
+// exists wraps the condition in an EXISTS subquery on the joined table.
+type exists struct {
+	join      Join
+	condition sq.Sqlizer
+}
+
+func (e *exists) ToSql() (string, []interface{}, error) {
+	sql, args, err := e.condition.ToSql()
+	if err != nil {
+		return "", nil, err
+	}
+	args = append(append([]any{}, e.join.Args...), args...)
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM %s WHERE %s AND %s)", e.join.Table, e.join.On, sql), args, nil
+}
+
+func (ctx *ApplyContext) join(alias string) (Join, bool) {
+	for _, j := range ctx.options.Joins {
+		if j.Alias == alias {
+			return j, true
+		}
+	}
+	return Join{}, false
+}
+
+// applyToManyJoins wraps the translated comparison condition in an EXISTS subquery if it references a to-many join.
+// A condition can reference only one to-many join, as the subquery selects from a single table.
+func (ctx *ApplyContext) applyToManyJoins(sqlObj any) (any, error) {
+	var toMany *Join
+	for _, alias := range ctx.leafAliases {
+		j, ok := ctx.join(alias)
+		if !ok || !j.ToMany {
+			continue
+		}
+		if toMany != nil && toMany.Alias != j.Alias {
+			return nil, fmt.Errorf("condition cannot reference the to-many joins %s and %s", toMany.Alias, j.Alias)
+		}
+		toMany = &j
+	}
+	if toMany == nil {
+		return sqlObj, nil
+	}
+	switch v := sqlObj.(type) {
+	case sq.Sqlizer:
+		return &exists{join: *toMany, condition: v}, nil
+	case []sq.Sqlizer:
+		return &exists{join: *toMany, condition: sq.And(v)}, nil
+	}
+	return nil, fmt.Errorf("unexpected data type: %T", sqlObj)
+}
+
+// requiredJoins returns the registered joins required by the referenced table aliases.
+// Dependencies come first, otherwise the joins keep the order in which they were registered.
+func (ctx *ApplyContext) requiredJoins() ([]Join, error) {
+	var required []Join
+	added := make(map[string]bool)
+	visiting := make(map[string]bool)
+	var add func(alias string) error
+	add = func(alias string) error {
+		if added[alias] {
+			return nil
+		}
+		if visiting[alias] {
+			return fmt.Errorf("cyclic join dependency on alias %s", alias)
+		}
+		j, ok := ctx.join(alias)
+		if !ok {
+			return fmt.Errorf("unknown join alias: %s", alias)
+		}
+		visiting[alias] = true
+		for _, dependency := range j.DependsOn {
+			if d, ok := ctx.join(dependency); ok && d.ToMany {
+				return fmt.Errorf("join %s cannot depend on to-many join %s", alias, dependency)
+			}
+			if err := add(dependency); err != nil {
+				return err
+			}
+		}
+		visiting[alias] = false
+		added[alias] = true
+		if !j.ToMany {
+			required = append(required, j)
+		}
+		return nil
+	}
+	for _, j := range ctx.options.Joins {
+		if !ctx.tableAliases[j.Alias] {
+			continue
+		}
+		if err := add(j.Alias); err != nil {
+			return nil, err
+		}
+	}
+	return required, nil
+}
+
+// applyJoins adds the registered joins required by the filter to the SELECT statement.
+func (ctx *ApplyContext) applyJoins(b sq.SelectBuilder) (sq.SelectBuilder, error) {
+	joins, err := ctx.requiredJoins()
+	if err != nil {
+		return b, err
+	}
+	for _, j := range joins {
+		b = b.JoinClause(j)
+	}
+	return b, nil
+}
@@ -0,0 +1,121 @@
+package filtersquirrel
+
+import (
+	"fmt"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/require"
+	"github.com/xafelium/filter"
+	"testing"
+)
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestApplyFilterWithJoins(t *testing.T) {
+	columns := map[string]string{
+		"name":    "u.name",
+		"city":    "a.city",
+		"country": "c.name",
+		"tag":     "t.name",
+		"role":    "r.name",
+		"group":   "g.name",
+	}
+	joins := []Option{
+		WithMapperFunc(func(fieldName string) (string, error) {
+			column, ok := columns[fieldName]
+			if !ok {
+				return "", fmt.Errorf("unknown field: %s", fieldName)
+			}
+			return column, nil
+		}),
+		WithJoin(Join{Alias: "c", Table: "countries c", On: "c.id = a.country_id", DependsOn: []string{"a"}}),
+		WithJoin(Join{Alias: "a", Type: LeftJoin, Table: "addresses a", On: "a.id = u.address_id"}),
+		WithJoin(Join{Alias: "t", Table: "tags t", On: "t.user_id = u.id AND t.deleted = ?", Args: []any{false}, ToMany: true}),
+		WithJoin(Join{Alias: "r", Table: "roles r", On: "r.user_id = u.id"}),
+	}
+
+	tests := []struct {
+		name         string
+		filter       filter.Condition
+		expectedSql  string
+		expectedArgs []any
+		errContains  string
+		opts         []Option
+	}{
+		{
+			name:         "no joins required",
+			filter:       filter.Where(filter.Equals("name", "a")),
+			expectedSql:  "SELECT u.* FROM users u WHERE u.name = $1",
+			expectedArgs: []any{"a"},
+		},
+		{
+			name:         "single join",
+			filter:       filter.Where(filter.Equals("city", "Berlin")),
+			expectedSql:  "SELECT u.* FROM users u LEFT JOIN addresses a ON a.id = u.address_id WHERE a.city = $1",
+			expectedArgs: []any{"Berlin"},
+		},
+		{
+			name: "dependencies are joined first",
+			filter: filter.Where(filter.And(
+				filter.Equals("role", "admin"),
+				filter.Equals("country", "Germany"),
+			)),
+			expectedSql: "SELECT u.* FROM users u " +
+				"LEFT JOIN addresses a ON a.id = u.address_id " +
+				"JOIN countries c ON c.id = a.country_id " +
+				"JOIN roles r ON r.user_id = u.id " +
+				"WHERE (r.name = $1 AND c.name = $2)",
+			expectedArgs: []any{"admin", "Germany"},
+		},
+		{
+			name: "to-many join renders EXISTS",
+			filter: filter.Where(filter.And(
+				filter.Equals("name", "a"),
+				filter.Not(filter.In("tag", []string{"x", "y"})),
+			)),
+			expectedSql:  "SELECT u.* FROM users u WHERE (u.name = $1 AND NOT (EXISTS (SELECT 1 FROM tags t WHERE t.user_id = u.id AND t.deleted = $2 AND t.name IN ($3,$4))))",
+			expectedArgs: []any{"a", false, "x", "y"},
+		},
+		{
+			name:        "condition on two to-many joins",
+			filter:      filter.Where(filter.Overlaps("membership", NewRange(1, 5))),
+			opts:        []Option{WithPeriod("membership", "tag", "group"), WithJoin(Join{Alias: "g", Table: "groups g", On: "g.user_id = u.id", ToMany: true})},
+			errContains: "condition cannot reference the to-many joins t and g",
+		},
+		{
+			name:        "dependency on to-many join",
+			filter:      filter.Where(filter.Equals("city", "Berlin")),
+			opts:        []Option{WithJoin(Join{Alias: "a", Table: "addresses a", On: "a.tag_id = t.id", DependsOn: []string{"t"}})},
+			errContains: "join a cannot depend on to-many join t",
+		},
+		{
+			name:        "unknown dependency",
+			filter:      filter.Where(filter.Equals("city", "Berlin")),
+			opts:        []Option{WithJoin(Join{Alias: "a", Table: "addresses a", On: "a.id = u.address_id", DependsOn: []string{"x"}})},
+			errContains: "unknown join alias: x",
+		},
+		{
+			name:   "cyclic dependency",
+			filter: filter.Where(filter.Equals("country", "Germany")),
+			opts: []Option{
+				WithJoin(Join{Alias: "a", Table: "addresses a", On: "a.id = c.address_id", DependsOn: []string{"c"}}),
+			},
+			errContains: "cyclic join dependency on alias c",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := append(append([]Option{}, joins...), test.opts...)
+			builder, _, err := ApplyFilter(sq.Select("u.*").From("users u").PlaceholderFormat(sq.Dollar), test.filter, opts...)
+
+			if test.errContains != "" {
+				require.ErrorContains(t, err, test.errContains)
+				return
+			}
+			require.NoError(t, err)
+			sql, args, err := builder.ToSql()
+			require.NoError(t, err)
+			require.Equal(t, test.expectedSql, sql)
+			require.Equal(t, test.expectedArgs, args)
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package filtersquirrel
+
+import (
+	"encoding/json"
+	"fmt"
+	sq "github.com/Masterminds/squirrel"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// jsonKind is the type a JSON value is extracted as.
+type jsonKind int
+
+const (
+	jsonText jsonKind = iota
+	jsonNumber
+	jsonBool
+)
+
+// jsonKindOf returns the kind to extract a JSON value as to compare it with value or the elements of a list value.
+func jsonKindOf(value any) jsonKind {
+	t := reflect.TypeOf(value)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		t = t.Elem()
+	}
+	if t == nil {
+		return jsonText
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return jsonBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return jsonNumber
+	}
+	return jsonText
+}
+
+// formatBools converts booleans and lists of booleans to the text of their JSON representation.
+func formatBools(value any) any {
+	switch v := value.(type) {
+	case bool:
+		return strconv.FormatBool(v)
+	case *bool:
+		if v == nil {
+			return nil
+		}
+		return strconv.FormatBool(*v)
+	}
+	if !isListType(value) {
+		return value
+	}
+	valVal := reflect.ValueOf(value)
+	formatted := make([]any, valVal.Len())
+	for i := range formatted {
+		formatted[i] = formatBools(valVal.Index(i).Interface())
+	}
+	return formatted
+}
+
+// jsonPathKey restricts the keys of JSON paths, as they are rendered into the SQL.
+var jsonPathKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func isArrayIndex(key string) bool {
+	_, err := strconv.ParseUint(key, 10, 32)
+	return err == nil
+}
+
+// jsonPathExpression renders the path in the SQL/JSON path syntax, e.g. $."a"[0]."b".
+func jsonPathExpression(path []string) string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, key := range path {
+		if isArrayIndex(key) {
+			b.WriteString("[" + key + "]")
+			continue
+		}
+		b.WriteString(`."` + key + `"`)
+	}
+	return b.String()
+}
+
+// postgresJSONPath renders the path with the -> operators, extracting the last element with ->> as text.
+func postgresJSONPath(column string, path []string) string {
+	var b strings.Builder
+	b.WriteString(column)
+	for i, key := range path {
+		b.WriteString("->")
+		if i == len(path)-1 {
+			b.WriteString(">")
+		}
+		if isArrayIndex(key) {
+			b.WriteString(key)
+			continue
+		}
+		b.WriteString("'" + key + "'")
+	}
+	return b.String()
+}
+
+// jsonField splits the domain field into the longest JSON field registered with WithJSONField and the path within it.
+func (ctx *ApplyContext) jsonField(field string) (string, []string, bool) {
+	return jsonFieldOf(ctx.options, field)
+}
+
+func jsonFieldOf(options *Options, field string) (string, []string, bool) {
+	if len(options.JSONFields) == 0 {
+		return "", nil, false
+	}
+	parts := strings.Split(field, ".")
+	for i := len(parts); i > 0; i-- {
+		prefix := strings.Join(parts[:i], ".")
+		if options.JSONFields[prefix] {
+			return prefix, parts[i:], true
+		}
+	}
+	return "", nil, false
+}
+
+// fieldOption returns the entry of the domain field in a per-field option like AllowedConditions. Paths into JSON
+// fields without an entry of their own get the entry of their JSON field.
+func fieldOption[T any](options *Options, entries map[string]T, field string) (T, bool) {
+	if entry, ok := entries[field]; ok {
+		return entry, true
+	}
+	if jsonField, path, ok := jsonFieldOf(options, field); ok && len(path) > 0 {
+		entry, ok := entries[jsonField]
+		return entry, ok
+	}
+	var zero T
+	return zero, false
+}
+
+// mapJSONColumn maps the JSON field to its column and validates the path.
+func (ctx *ApplyContext) mapJSONColumn(field string, jsonField string, path []string) (string, error) {
+	for _, key := range path {
+		if !jsonPathKey.MatchString(key) {
+			return "", fmt.Errorf("%w: invalid JSON path key %q in field %s", ErrUnknownField, key, field)
+		}
+	}
+	column, err := ctx.mapperFunc(jsonField)
+	if err != nil {
+		return "", err
+	}
+	ctx.recordTableAlias(column, field)
+	return column, nil
+}
+
+// mapFieldValue maps the domain field to its column like MapField and coerces the value with its FieldType.
+// Paths into JSON fields are extracted with the type of the value, which is converted for the comparison if the
+// dialect requires it.
+func (ctx *ApplyContext) mapFieldValue(field string, value any) (string, any, error) {
+	value, err := ctx.coerceValue(field, value)
+	if err != nil {
+		return "", nil, err
+	}
+	jsonField, path, ok := ctx.jsonField(field)
+	if !ok || len(path) == 0 {
+		fieldName, err := ctx.mapperFunc(field)
+		if err != nil {
+			return "", nil, err
+		}
+		ctx.recordTableAlias(fieldName, field)
+		return fieldName, value, nil
+	}
+	column, err := ctx.mapJSONColumn(field, jsonField, path)
+	if err != nil {
+		return "", nil, err
+	}
+	fieldName, value := ctx.Dialect().JSONPath(column, path, value)
+	return fieldName, value, nil
+}
+
+// mapArrayField maps the domain field of an array condition, which cannot be applied to paths into JSON fields.
+func (ctx *ApplyContext) mapArrayField(field string) (string, error) {
+	if _, path, ok := ctx.jsonField(field); ok && len(path) > 0 {
+		return "", fmt.Errorf("array conditions are not supported on JSON field %s", field)
+	}
+	return ctx.MapField(field)
+}
+
+// isJSONObject reports whether the value is a nested object compared with a JSON field.
+func (ctx *ApplyContext) isJSONObject(field string, value any) bool {
+	if _, _, ok := ctx.jsonField(field); !ok {
+		return false
+	}
+	t := reflect.TypeOf(value)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t != nil && (t.Kind() == reflect.Map || (t.Kind() == reflect.Struct && !isValueStruct(t)))
+}
+
+// jsonContains matches the JSON field if it contains the object at the path of the domain field.
+func (ctx *ApplyContext) jsonContains(field string, value any) (sq.Sqlizer, error) {
+	jsonField, path, _ := ctx.jsonField(field)
+	column, err := ctx.mapJSONColumn(field, jsonField, path)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(path) - 1; i >= 0; i-- {
+		if isArrayIndex(path[i]) {
+			return nil, fmt.Errorf("%w: cannot compare object at array index in field %s", ErrInvalidValue, field)
+		}
+		value = map[string]any{path[i]: value}
+	}
+	document, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return ctx.Dialect().JSONContains(column, string(document))
+}
@@ -0,0 +1,198 @@
+package filtersquirrel
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/require"
+	"github.com/xafelium/filter"
+	"testing"
+)
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestApplyFilterWithJSONFields(t *testing.T) {
+	tests := []struct {
+		name                 string
+		dialect              Dialect
+		mapperFunc           FieldMapperFunc
+		filter               filter.Condition
+		expectedSql          string
+		expectedArgs         []any
+		expectedTableAliases []string
+		errContains          string
+	}{
+		{
+			name:         "postgres text",
+			dialect:      Postgres,
+			filter:       filter.Where(filter.Equals("attributes.color", "red")),
+			expectedSql:  "SELECT * FROM products WHERE attributes->>'color' = $1",
+			expectedArgs: []any{"red"},
+		},
+		{
+			name:         "postgres nested number",
+			dialect:      Postgres,
+			filter:       filter.Where(filter.GreaterThan("attributes.size.width", 3)),
+			expectedSql:  "SELECT * FROM products WHERE (attributes->'size'->>'width')::numeric > $1",
+			expectedArgs: []any{3},
+		},
+		{
+			name:         "postgres boolean",
+			dialect:      Postgres,
+			filter:       filter.Where(filter.Equals("attributes.active", true)),
+			expectedSql:  "SELECT * FROM products WHERE (attributes->>'active')::boolean = $1",
+			expectedArgs: []any{true},
+		},
+		{
+			name:         "postgres array index",
+			dialect:      Postgres,
+			filter:       filter.Where(filter.Equals("attributes.tags.0", "new")),
+			expectedSql:  "SELECT * FROM products WHERE attributes->'tags'->>0 = $1",
+			expectedArgs: []any{"new"},
+		},
+		{
+			name:         "postgres in numbers",
+			dialect:      Postgres,
+			filter:       filter.Where(filter.In("attributes.size", []float64{1.5, 2})),
+			expectedSql:  "SELECT * FROM products WHERE (attributes->>'size')::numeric IN ($1,$2)",
+			expectedArgs: []any{1.5, float64(2)},
+		},
+		{
+			name:         "postgres contains",
+			dialect:      Postgres,
+			filter:       filter.Where(filter.Contains("attributes.color", "re")),
+			expectedSql:  "SELECT * FROM products WHERE attributes->>'color' ILIKE $1",
+			expectedArgs: []any{"%re%"},
+		},
+		{
+			name:        "postgres is nil",
+			dialect:     Postgres,
+			filter:      filter.Where(filter.IsNil("attributes.color")),
+			expectedSql: "SELECT * FROM products WHERE attributes->>'color' IS NULL",
+		},
+		{
+			name:         "postgres nested object",
+			dialect:      Postgres,
+			filter:       filter.Where(filter.Equals("attributes.size", map[string]any{"width": 3})),
+			expectedSql:  "SELECT * FROM products WHERE attributes @> $1::jsonb",
+			expectedArgs: []any{`{"size":{"width":3}}`},
+		},
+		{
+			name:         "postgres object",
+			dialect:      Postgres,
+			filter:       filter.Where(filter.Equals("attributes", map[string]any{"color": "red"})),
+			expectedSql:  "SELECT * FROM products WHERE attributes @> $1::jsonb",
+			expectedArgs: []any{`{"color":"red"}`},
+		},
+		{
+			name:    "table alias of the JSON column",
+			dialect: Postgres,
+			mapperFunc: func(fieldName string) (string, error) {
+				return "p." + fieldName, nil
+			},
+			filter: filter.Where(filter.And(
+				filter.Equals("attributes.color", "red"),
+				filter.Equals("attributes.size", map[string]any{"width": 3}),
+			)),
+			expectedSql:          "SELECT * FROM products WHERE (p.attributes->>'color' = $1 AND p.attributes @> $2::jsonb)",
+			expectedArgs:         []any{"red", `{"size":{"width":3}}`},
+			expectedTableAliases: []string{"p"},
+		},
+		{
+			name:    "JSON path without table alias",
+			dialect: Postgres,
+			mapperFunc: func(fieldName string) (string, error) {
+				return "attributes->>'color.name'", nil
+			},
+			filter:       filter.Where(filter.Equals("color", "red")),
+			expectedSql:  "SELECT * FROM products WHERE attributes->>'color.name' = $1",
+			expectedArgs: []any{"red"},
+		},
+		{
+			name:         "mysql text",
+			dialect:      MySQL,
+			filter:       filter.Where(filter.Equals("attributes.color", "red")),
+			expectedSql:  `SELECT * FROM products WHERE JSON_UNQUOTE(JSON_EXTRACT(attributes, '$."color"')) = ?`,
+			expectedArgs: []any{"red"},
+		},
+		{
+			name:         "mysql number",
+			dialect:      MySQL,
+			filter:       filter.Where(filter.LowerThan("attributes.size.width", 3)),
+			expectedSql:  `SELECT * FROM products WHERE JSON_EXTRACT(attributes, '$."size"."width"') < ?`,
+			expectedArgs: []any{3},
+		},
+		{
+			name:         "mysql boolean",
+			dialect:      MySQL,
+			filter:       filter.Where(filter.NotEquals("attributes.active", true)),
+			expectedSql:  `SELECT * FROM products WHERE JSON_UNQUOTE(JSON_EXTRACT(attributes, '$."active"')) <> ?`,
+			expectedArgs: []any{"true"},
+		},
+		{
+			name:         "mysql object",
+			dialect:      MySQL,
+			filter:       filter.Where(filter.Equals("attributes.size", map[string]any{"width": 3})),
+			expectedSql:  "SELECT * FROM products WHERE JSON_CONTAINS(attributes, ?)",
+			expectedArgs: []any{`{"size":{"width":3}}`},
+		},
+		{
+			name:         "sqlite array index",
+			dialect:      SQLite,
+			filter:       filter.Where(filter.Equals("attributes.tags.0", "new")),
+			expectedSql:  `SELECT * FROM products WHERE json_extract(attributes, '$."tags"[0]') = ?`,
+			expectedArgs: []any{"new"},
+		},
+		{
+			name:        "sqlite object",
+			dialect:     SQLite,
+			filter:      filter.Where(filter.Equals("attributes.size", map[string]any{"width": 3})),
+			errContains: "JSON containment cannot be expressed in sqlite",
+		},
+		{
+			name:         "sqlserver number",
+			dialect:      SQLServer,
+			filter:       filter.Where(filter.GreaterThanOrEqual("attributes.size", 2)),
+			expectedSql:  `SELECT * FROM products WHERE CAST(JSON_VALUE(attributes, '$."size"') AS FLOAT) >= ?`,
+			expectedArgs: []any{2},
+		},
+		{
+			name:         "sqlserver booleans",
+			dialect:      SQLServer,
+			filter:       filter.Where(filter.In("attributes.active", []bool{true, false})),
+			expectedSql:  `SELECT * FROM products WHERE JSON_VALUE(attributes, '$."active"') IN (?,?)`,
+			expectedArgs: []any{"true", "false"},
+		},
+		{
+			name:        "invalid path key",
+			dialect:     Postgres,
+			filter:      filter.Where(filter.Equals("attributes.color'", "red")),
+			errContains: `invalid JSON path key "color'" in field attributes.color'`,
+		},
+		{
+			name:        "array condition",
+			dialect:     Postgres,
+			filter:      filter.Where(filter.ArrayContains("attributes.tags", "new")),
+			errContains: "array conditions are not supported on JSON field attributes.tags",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			builder := sq.Select("*").From("products")
+			if test.dialect == Postgres {
+				builder = builder.PlaceholderFormat(sq.Dollar)
+			}
+			builder, tableAliases, err := ApplyFilter(builder, test.filter,
+				WithDialect(test.dialect), WithMapperFunc(test.mapperFunc), WithJSONField("attributes"))
+
+			if test.errContains != "" {
+				require.ErrorContains(t, err, test.errContains)
+				return
+			}
+			require.NoError(t, err)
+			sql, args, err := builder.ToSql()
+			require.NoError(t, err)
+			require.Equal(t, test.expectedSql, sql)
+			require.Equal(t, test.expectedArgs, args)
+			require.Equal(t, test.expectedTableAliases, tableAliases)
+		})
+	}
+}
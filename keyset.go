@@ -0,0 +1,186 @@
+package filtersquirrel
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/xafelium/filter"
+	"strings"
+)
+
+// cursorToken is the JSON representation of an encoded cursor.
+type cursorToken struct {
+	Sort   string `json:"s"`
+	Values []any  `json:"v"`
+}
+
+// EncodeCursor encodes the sort values of the last row of a page into an opaque token.
+func EncodeCursor(sort []SortField, values []any) (string, error) {
+	if len(values) != len(sort) {
+		return "", fmt.Errorf("expected %d cursor values but got %d", len(sort), len(values))
+	}
+	data, err := json.Marshal(cursorToken{Sort: sortKey(sort), Values: values})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCursor decodes a token created by EncodeCursor for the same sort fields.
+// Integral numbers are decoded as int64, other numbers as float64 and times as strings.
+func DecodeCursor(token string, sort []SortField) ([]any, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var c cursorToken
+	if err := decoder.Decode(&c); err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+	if c.Sort != sortKey(sort) {
+		return nil, fmt.Errorf("invalid cursor: created for sort %q", c.Sort)
+	}
+	if len(c.Values) != len(sort) {
+		return nil, fmt.Errorf("invalid cursor: expected %d values but got %d", len(sort), len(c.Values))
+	}
+	for i, v := range c.Values {
+		n, ok := v.(json.Number)
+		if !ok {
+			continue
+		}
+		if c.Values[i], err = n.Int64(); err != nil {
+			if c.Values[i], err = n.Float64(); err != nil {
+				return nil, fmt.Errorf("invalid cursor: %w", err)
+			}
+		}
+	}
+	return c.Values, nil
+}
+
+// sortKey identifies the sort fields, e.g. "-created_at,id".
+func sortKey(sort []SortField) string {
+	keys := make([]string, len(sort))
+	for i, s := range sort {
+		if s.Direction == Desc {
+			keys[i] = "-" + s.Field
+		} else {
+			keys[i] = s.Field
+		}
+	}
+	return strings.Join(keys, ",")
+}
+
+// KeysetPredicate returns the condition selecting the rows after the row with the given sort values.
+// The sort fields must not be nullable and should end with a unique field.
+func KeysetPredicate(sort []SortField, after []any, opts ...Option) (sq.Sqlizer, []string, error) {
+	ctx := newApplyContext(FromDefaultOptions(opts...))
+	predicate, err := ctx.keysetPredicate(sort, after)
+	if err != nil {
+		return nil, nil, err
+	}
+	return predicate, ctx.tableAliasList(), nil
+}
+
+// ApplyKeyset applies the filter, the sort fields and the keyset predicate for the rows after the row
+// with the given sort values. It applies the first page if after is empty.
+func ApplyKeyset(b sq.SelectBuilder, condition filter.Condition, sort []SortField, after []any, opts ...Option) (sq.SelectBuilder, []string, error) {
+	sqlizer, ctx, err := buildFilter(condition, FromDefaultOptions(opts...))
+	if err != nil {
+		return b, nil, err
+	}
+	orderBys, err := ctx.applySort(sort)
+	if err != nil {
+		return b, nil, err
+	}
+	var predicate sq.Sqlizer
+	if len(after) > 0 {
+		if predicate, err = ctx.keysetPredicate(sort, after); err != nil {
+			return b, nil, err
+		}
+	}
+	b, err = ctx.applyJoins(b)
+	if err != nil {
+		return b, nil, err
+	}
+	if sqlizer != nil {
+		b = b.Where(sqlizer)
+	}
+	if predicate != nil {
+		b = b.Where(predicate)
+	}
+	return b.OrderBy(orderBys...), ctx.tableAliasList(), nil
+}
+
+func (ctx *ApplyContext) keysetPredicate(sort []SortField, after []any) (sq.Sqlizer, error) {
+	if len(sort) == 0 {
+		return nil, fmt.Errorf("keyset pagination requires at least one sort field")
+	}
+	if len(after) != len(sort) {
+		return nil, fmt.Errorf("expected %d cursor values but got %d", len(sort), len(after))
+	}
+	columns := make([]string, len(sort))
+	directions := make([]SortDirection, len(sort))
+	values := make([]any, len(after))
+	sameDirection := true
+	for i, s := range sort {
+		switch s.Direction {
+		case "", Asc:
+			directions[i] = Asc
+		case Desc:
+			directions[i] = Desc
+		default:
+			return nil, fmt.Errorf("invalid sort direction for field %s: %s", s.Field, s.Direction)
+		}
+		switch s.Nulls {
+		case NullsDefault:
+		case NullsFirst, NullsLast:
+			return nil, fmt.Errorf("keyset pagination does not support nulls order for field %s", s.Field)
+		default:
+			return nil, fmt.Errorf("invalid nulls order for field %s: %s", s.Field, s.Nulls)
+		}
+		column, err := ctx.MapField(s.Field)
+		if err != nil {
+			return nil, err
+		}
+		// Cursor values are decoded from JSON, e.g. times as strings, and are coerced like filter values.
+		if values[i], err = ctx.coerceValue(s.Field, after[i]); err != nil {
+			return nil, err
+		}
+		columns[i] = column
+		sameDirection = sameDirection && directions[i] == directions[0]
+	}
+
+	if sameDirection && len(sort) > 1 && ctx.Dialect().SupportsRowValues() {
+		return sq.Expr(
+			fmt.Sprintf("(%s) %s (%s)", strings.Join(columns, ", "), keysetOperator(directions[0]), sq.Placeholders(len(values))),
+			values...,
+		), nil
+	}
+
+	// (a > ? OR (a = ? AND b > ?) OR ...)
+	or := sq.Or{}
+	for i := range sort {
+		and := sq.And{}
+		for j := 0; j < i; j++ {
+			and = append(and, sq.Eq{columns[j]: values[j]})
+		}
+		and = append(and, sq.Expr(fmt.Sprintf("%s %s ?", columns[i], keysetOperator(directions[i])), values[i]))
+		if len(and) == 1 {
+			or = append(or, and[0])
+		} else {
+			or = append(or, and)
+		}
+	}
+	return or, nil
+}
+
+func keysetOperator(direction SortDirection) string {
+	if direction == Desc {
+		return "<"
+	}
+	return ">"
+}
@@ -0,0 +1,175 @@
+package filtersquirrel
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/require"
+	"github.com/xafelium/filter"
+	"testing"
+	"time"
+)
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestKeysetPredicate(t *testing.T) {
+	tests := []struct {
+		name         string
+		sort         []SortField
+		after        []any
+		opts         []Option
+		expectedSql  string
+		expectedArgs []any
+		errContains  string
+	}{
+		{
+			name:         "single field",
+			sort:         []SortField{{Field: "id"}},
+			after:        []any{10},
+			expectedSql:  "(id > ?)",
+			expectedArgs: []any{10},
+		},
+		{
+			name:         "row values ascending",
+			sort:         []SortField{{Field: "created_at"}, {Field: "id"}},
+			after:        []any{"2024-01-01", 10},
+			expectedSql:  "(created_at, id) > (?,?)",
+			expectedArgs: []any{"2024-01-01", 10},
+		},
+		{
+			name:         "row values descending",
+			sort:         []SortField{{Field: "created_at", Direction: Desc}, {Field: "id", Direction: Desc}},
+			after:        []any{"2024-01-01", 10},
+			expectedSql:  "(created_at, id) < (?,?)",
+			expectedArgs: []any{"2024-01-01", 10},
+		},
+		{
+			name:         "mixed directions",
+			sort:         []SortField{{Field: "created_at", Direction: Desc}, {Field: "name"}, {Field: "id"}},
+			after:        []any{"2024-01-01", "x", 10},
+			expectedSql:  "(created_at < ? OR (created_at = ? AND name > ?) OR (created_at = ? AND name = ? AND id > ?))",
+			expectedArgs: []any{"2024-01-01", "2024-01-01", "x", "2024-01-01", "x", 10},
+		},
+		{
+			name:         "dialect without row values",
+			sort:         []SortField{{Field: "created_at"}, {Field: "id"}},
+			after:        []any{"2024-01-01", 10},
+			opts:         []Option{WithDialect(SQLServer)},
+			expectedSql:  "(created_at > ? OR (created_at = ? AND id > ?))",
+			expectedArgs: []any{"2024-01-01", "2024-01-01", 10},
+		},
+		{
+			name:         "values coerced with field types",
+			sort:         []SortField{{Field: "created_at"}, {Field: "id"}},
+			after:        []any{"2024-01-01T10:00:00Z", int64(10)},
+			opts:         []Option{WithDialect(SQLite), WithFieldType(TimeType, "created_at"), WithFieldType(StringType, "id")},
+			expectedSql:  "(created_at, id) > (?,?)",
+			expectedArgs: []any{time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), "10"},
+		},
+		{
+			name:        "invalid cursor value",
+			sort:        []SortField{{Field: "created_at"}},
+			after:       []any{"yesterday"},
+			opts:        []Option{WithFieldType(TimeType, "created_at")},
+			errContains: "invalid value for field created_at",
+		},
+		{
+			name:        "invalid direction",
+			sort:        []SortField{{Field: "id", Direction: "sideways"}},
+			after:       []any{10},
+			errContains: "invalid sort direction for field id: sideways",
+		},
+		{
+			name:        "invalid nulls order",
+			sort:        []SortField{{Field: "id", Nulls: "NULLS MIDDLE"}},
+			after:       []any{10},
+			errContains: "invalid nulls order for field id: NULLS MIDDLE",
+		},
+		{
+			name:         "default and explicit ascending direction",
+			sort:         []SortField{{Field: "created_at"}, {Field: "id", Direction: Asc}},
+			after:        []any{"2024-01-01", 10},
+			expectedSql:  "(created_at, id) > (?,?)",
+			expectedArgs: []any{"2024-01-01", 10},
+		},
+		{
+			name:        "missing values",
+			sort:        []SortField{{Field: "created_at"}, {Field: "id"}},
+			after:       []any{"2024-01-01"},
+			errContains: "expected 2 cursor values but got 1",
+		},
+		{
+			name:        "nulls order",
+			sort:        []SortField{{Field: "created_at", Nulls: NullsLast}},
+			after:       []any{"2024-01-01"},
+			errContains: "keyset pagination does not support nulls order for field created_at",
+		},
+		{
+			name:        "no sort",
+			errContains: "keyset pagination requires at least one sort field",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			predicate, _, err := KeysetPredicate(test.sort, test.after, test.opts...)
+
+			if test.errContains != "" {
+				require.ErrorContains(t, err, test.errContains)
+				return
+			}
+			require.NoError(t, err)
+			sql, args, err := predicate.ToSql()
+			require.NoError(t, err)
+			require.Equal(t, test.expectedSql, sql)
+			require.Equal(t, test.expectedArgs, args)
+		})
+	}
+}
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestApplyKeyset(t *testing.T) {
+	sort := []SortField{{Field: "created_at", Direction: Desc}, {Field: "id", Direction: Desc}}
+	mapperFunc := WithMapperFunc(func(fieldName string) (string, error) {
+		return "u." + fieldName, nil
+	})
+	base := sq.Select("u.*").From("users u").Limit(20).PlaceholderFormat(sq.Dollar)
+	condition := filter.Where(filter.Equals("status", "active"))
+
+	builder, _, err := ApplyKeyset(base, condition, sort, nil, mapperFunc)
+	require.NoError(t, err)
+	sql, args, err := builder.ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT u.* FROM users u WHERE u.status = $1 ORDER BY u.created_at DESC, u.id DESC LIMIT 20", sql)
+	require.Equal(t, []any{"active"}, args)
+
+	token, err := EncodeCursor(sort, []any{"2024-01-01T00:00:00Z", 42})
+	require.NoError(t, err)
+	after, err := DecodeCursor(token, sort)
+	require.NoError(t, err)
+	require.Equal(t, []any{"2024-01-01T00:00:00Z", int64(42)}, after)
+
+	builder, tableAliases, err := ApplyKeyset(base, condition, sort, after, mapperFunc)
+	require.NoError(t, err)
+	sql, args, err = builder.ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT u.* FROM users u WHERE u.status = $1 AND (u.created_at, u.id) < ($2,$3) ORDER BY u.created_at DESC, u.id DESC LIMIT 20", sql)
+	require.Equal(t, []any{"active", "2024-01-01T00:00:00Z", int64(42)}, args)
+	require.Equal(t, []string{"u"}, tableAliases)
+}
+
+func TestDecodeCursor(t *testing.T) {
+	sort := []SortField{{Field: "score"}, {Field: "id"}}
+	token, err := EncodeCursor(sort, []any{1.5, "abc"})
+	require.NoError(t, err)
+
+	values, err := DecodeCursor(token, sort)
+	require.NoError(t, err)
+	require.Equal(t, []any{1.5, "abc"}, values)
+
+	_, err = DecodeCursor(token, []SortField{{Field: "score", Direction: Desc}, {Field: "id"}})
+	require.ErrorContains(t, err, `invalid cursor: created for sort "score,id"`)
+
+	_, err = DecodeCursor("not a cursor!", sort)
+	require.ErrorContains(t, err, "invalid cursor")
+
+	_, err = EncodeCursor(sort, []any{1})
+	require.ErrorContains(t, err, "expected 2 cursor values but got 1")
+}
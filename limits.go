@@ -0,0 +1,110 @@
+package filtersquirrel
+
+import (
+	"fmt"
+	"github.com/xafelium/filter"
+	"reflect"
+)
+
+// Limit names reported by LimitError.
+const (
+	LimitDepth       = "depth"
+	LimitConditions  = "conditions"
+	LimitInValues    = "in values"
+	LimitArrayValues = "array values"
+	LimitRegex       = "regex"
+)
+
+// LimitError is returned if a filter exceeds one of the configured complexity limits.
+type LimitError struct {
+	// Limit is the name of the exceeded limit, e.g. LimitDepth.
+	Limit string
+	// Max is the configured maximum.
+	Max int
+	// Actual is the value which exceeded the maximum.
+	Actual int
+}
+
+func (e *LimitError) Error() string {
+	if e.Limit == LimitRegex {
+		return "filter too complex: regex conditions are not allowed"
+	}
+	return fmt.Sprintf("filter too complex: %s %d exceeds maximum of %d", e.Limit, e.Actual, e.Max)
+}
+
+// Is reports whether the target is ErrTooComplex.
+func (e *LimitError) Is(target error) bool {
+	return target == ErrTooComplex
+}
+
+// limitState tracks the complexity of the filter while it is translated.
+type limitState struct {
+	depth      int
+	conditions int
+}
+
+// enter is called before a condition is translated and checks the depth, condition and regex limits.
+func (ctx *ApplyContext) enter(condition filter.Condition) error {
+	o := ctx.options
+	if !isWhereCondition(condition) {
+		ctx.limits.depth++
+		if o.MaxDepth > 0 && ctx.limits.depth > o.MaxDepth {
+			return &LimitError{Limit: LimitDepth, Max: o.MaxDepth, Actual: ctx.limits.depth}
+		}
+	}
+	if isLeafCondition(condition) {
+		ctx.limits.conditions++
+		if o.MaxConditions > 0 && ctx.limits.conditions > o.MaxConditions {
+			return &LimitError{Limit: LimitConditions, Max: o.MaxConditions, Actual: ctx.limits.conditions}
+		}
+	}
+	if !o.RegexAllowed {
+		switch condition.Type() {
+		case filter.RegexConditionType, filter.NotRegexConditionType:
+			return &LimitError{Limit: LimitRegex}
+		}
+	}
+	return nil
+}
+
+// leave is called after a condition has been translated.
+func (ctx *ApplyContext) leave(condition filter.Condition) {
+	if !isWhereCondition(condition) {
+		ctx.limits.depth--
+	}
+}
+
+func (ctx *ApplyContext) checkInValues(value any) error {
+	return checkValuesLimit(LimitInValues, ctx.options.MaxInValues, value)
+}
+
+func (ctx *ApplyContext) checkArrayValues(value any) error {
+	return checkValuesLimit(LimitArrayValues, ctx.options.MaxArrayValues, value)
+}
+
+func checkValuesLimit(limit string, max int, value any) error {
+	if max <= 0 || !isListType(value) {
+		return nil
+	}
+	if n := reflect.ValueOf(value).Len(); n > max {
+		return &LimitError{Limit: limit, Max: max, Actual: n}
+	}
+	return nil
+}
+
+func isWhereCondition(condition filter.Condition) bool {
+	return condition.Type() == filter.WhereConditionType
+}
+
+// isLeafCondition reports whether the condition is a comparison rather than a logical condition.
+func isLeafCondition(condition filter.Condition) bool {
+	switch condition.Type() {
+	case filter.WhereConditionType,
+		filter.GroupConditionType,
+		filter.NotConditionType,
+		filter.AndConditionType,
+		filter.OrConditionType:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,116 @@
+package filtersquirrel
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/require"
+	"github.com/xafelium/filter"
+	"testing"
+)
+
+func TestApplyFilterWithLimits(t *testing.T) {
+	tests := []struct {
+		name          string
+		filter        filter.Condition
+		opts          []Option
+		expectedError *LimitError
+	}{
+		{
+			name: "depth within limit",
+			filter: filter.Where(filter.And(
+				filter.Equals("a", 1),
+				filter.Not(filter.Equals("b", 2)),
+			)),
+			opts: []Option{WithMaxDepth(3)},
+		},
+		{
+			name: "depth exceeded",
+			filter: filter.Where(filter.And(
+				filter.Equals("a", 1),
+				filter.Group(filter.Not(filter.Equals("b", 2))),
+			)),
+			opts:          []Option{WithMaxDepth(3)},
+			expectedError: &LimitError{Limit: LimitDepth, Max: 3, Actual: 4},
+		},
+		{
+			name: "conditions within limit",
+			filter: filter.Where(filter.Or(
+				filter.Equals("a", 1),
+				filter.Equals("b", 2),
+			)),
+			opts: []Option{WithMaxConditions(2)},
+		},
+		{
+			name: "conditions exceeded",
+			filter: filter.Where(filter.Or(
+				filter.Equals("a", 1),
+				filter.And(filter.Equals("b", 2), filter.IsNil("c")),
+			)),
+			opts:          []Option{WithMaxConditions(2)},
+			expectedError: &LimitError{Limit: LimitConditions, Max: 2, Actual: 3},
+		},
+		{
+			name:   "in values within limit",
+			filter: filter.In("id", []int{1, 2, 3}),
+			opts:   []Option{WithMaxInValues(3)},
+		},
+		{
+			name:          "in values exceeded",
+			filter:        filter.In("id", []int{1, 2, 3, 4}),
+			opts:          []Option{WithMaxInValues(3)},
+			expectedError: &LimitError{Limit: LimitInValues, Max: 3, Actual: 4},
+		},
+		{
+			name:          "equals values exceeded",
+			filter:        filter.Equals("id", []int{1, 2, 3, 4}),
+			opts:          []Option{WithMaxInValues(3)},
+			expectedError: &LimitError{Limit: LimitInValues, Max: 3, Actual: 4},
+		},
+		{
+			name:          "not equals values exceeded",
+			filter:        filter.NotEquals("id", []int{1, 2, 3, 4}),
+			opts:          []Option{WithMaxInValues(3), WithNullAwareNegation(true)},
+			expectedError: &LimitError{Limit: LimitInValues, Max: 3, Actual: 4},
+		},
+		{
+			name:          "array values exceeded",
+			filter:        filter.ArraysOverlap("tags", []string{"a", "b", "c"}),
+			opts:          []Option{WithMaxArrayValues(2)},
+			expectedError: &LimitError{Limit: LimitArrayValues, Max: 2, Actual: 3},
+		},
+		{
+			name:          "array contains array values exceeded",
+			filter:        filter.ArrayContainsArray("tags", []string{"a", "b", "c"}),
+			opts:          []Option{WithMaxArrayValues(2)},
+			expectedError: &LimitError{Limit: LimitArrayValues, Max: 2, Actual: 3},
+		},
+		{
+			name:          "regex not allowed",
+			filter:        filter.Where(filter.Not(filter.NotRegex("name", ".*"))),
+			opts:          []Option{WithRegexAllowed(false)},
+			expectedError: &LimitError{Limit: LimitRegex},
+		},
+		{
+			name:   "regex allowed by default",
+			filter: filter.Where(filter.Regex("name", ".*")),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, _, err := ApplyFilter(sq.Select("*").From("x"), test.filter, test.opts...)
+
+			if test.expectedError == nil {
+				require.NoError(t, err)
+				return
+			}
+			var limitErr *LimitError
+			require.ErrorAs(t, err, &limitErr)
+			require.Equal(t, test.expectedError, limitErr)
+		})
+	}
+}
+
+func TestLimitErrorMessage(t *testing.T) {
+	require.EqualError(t, &LimitError{Limit: LimitDepth, Max: 3, Actual: 4}, "filter too complex: depth 4 exceeds maximum of 3")
+	require.EqualError(t, &LimitError{Limit: LimitRegex}, "filter too complex: regex conditions are not allowed")
+}
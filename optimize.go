@@ -0,0 +1,259 @@
+package filtersquirrel
+
+import (
+	"github.com/xafelium/filter"
+	"reflect"
+)
+
+// constant is the value of a condition which does not depend on the row.
+type constant int8
+
+const (
+	notConstant constant = iota
+	constFalse
+	constTrue
+)
+
+func (c constant) not() constant {
+	switch c {
+	case constFalse:
+		return constTrue
+	case constTrue:
+		return constFalse
+	}
+	return notConstant
+}
+
+// optimizer normalizes a filter tree without changing its result in SQL's three-valued logic.
+type optimizer struct {
+	options *Options
+}
+
+// optimize returns the normalized condition, see WithOptimize. A filter which matches all rows is replaced by
+// an empty WHERE condition.
+func optimize(condition filter.Condition, options *Options) filter.Condition {
+	if condition == nil {
+		return nil
+	}
+	o := &optimizer{options: options}
+	c, k := o.optimize(condition)
+	if k == constTrue {
+		return filter.Where(nil)
+	}
+	return c
+}
+
+// optimize returns the normalized condition and whether it is constant. Constant conditions are represented by
+// a condition with the constant value, e.g. an OverlapsCondition without values for false.
+func (o *optimizer) optimize(condition filter.Condition) (filter.Condition, constant) {
+	switch c := condition.(type) {
+	case *filter.WhereCondition:
+		if c.Condition == nil {
+			return c, notConstant
+		}
+		inner, k := o.optimize(c.Condition)
+		return filter.Where(inner), k
+	case *filter.GroupCondition:
+		if c.Condition == nil {
+			return c, notConstant
+		}
+		return o.optimize(c.Condition)
+	case *filter.NotCondition:
+		inner, k := o.optimize(c.Condition)
+		if n, ok := inner.(*filter.NotCondition); ok {
+			return n.Condition, k.not()
+		}
+		return filter.Not(inner), k.not()
+	case *filter.AndCondition:
+		if len(c.Conditions) < 2 {
+			return c, notConstant
+		}
+		return o.optimizeConjunction(c.Conditions, constFalse)
+	case *filter.OrCondition:
+		if len(c.Conditions) < 2 {
+			return c, notConstant
+		}
+		return o.optimizeConjunction(c.Conditions, constTrue)
+	}
+	if isConstantFalse(condition) {
+		return condition, constFalse
+	}
+	return condition, notConstant
+}
+
+// optimizeConjunction flattens, folds and deduplicates the conditions of an AND or OR, whose result is decided by
+// the dominant constant.
+func (o *optimizer) optimizeConjunction(conditions []filter.Condition, dominant constant) (filter.Condition, constant) {
+	var (
+		optimized []filter.Condition
+		neutral   filter.Condition
+	)
+	add := func(c filter.Condition) {
+		for _, existing := range optimized {
+			if reflect.DeepEqual(existing, c) {
+				return
+			}
+		}
+		optimized = append(optimized, c)
+	}
+	for _, condition := range conditions {
+		c, k := o.optimize(condition)
+		switch k {
+		case dominant:
+			return c, k
+		case dominant.not():
+			neutral = c
+			continue
+		}
+		switch {
+		case dominant == constFalse && isAnd(c):
+			for _, nested := range c.(*filter.AndCondition).Conditions {
+				add(nested)
+			}
+		case dominant == constTrue && isOr(c):
+			for _, nested := range c.(*filter.OrCondition).Conditions {
+				add(nested)
+			}
+		default:
+			add(c)
+		}
+	}
+	if dominant == constTrue {
+		optimized = o.mergeEquals(optimized)
+	}
+	switch len(optimized) {
+	case 0:
+		return neutral, dominant.not()
+	case 1:
+		return optimized[0], notConstant
+	}
+	if dominant == constTrue {
+		return filter.Or(optimized...), notConstant
+	}
+	return filter.And(optimized...), notConstant
+}
+
+func isAnd(c filter.Condition) bool {
+	_, ok := c.(*filter.AndCondition)
+	return ok
+}
+
+func isOr(c filter.Condition) bool {
+	_, ok := c.(*filter.OrCondition)
+	return ok
+}
+
+// mergeEquals merges the Equals and In conditions of the same field in an OR into a single In condition.
+// Values of different types are not merged, as they may be compared differently, e.g. in JSON fields.
+func (o *optimizer) mergeEquals(conditions []filter.Condition) []filter.Condition {
+	values := make(map[string][]any)
+	count := make(map[string]int)
+	for _, c := range conditions {
+		if field, v, ok := equalsValues(c); ok {
+			values[field] = append(values[field], v...)
+			count[field]++
+		}
+	}
+	var merged []filter.Condition
+	for _, c := range conditions {
+		field, _, ok := equalsValues(c)
+		if !ok || count[field] < 2 {
+			merged = append(merged, c)
+			continue
+		}
+		in, ok := o.mergedIn(field, values[field])
+		if !ok {
+			merged = append(merged, c)
+			continue
+		}
+		if in != nil {
+			merged = append(merged, in)
+			values[field] = nil
+		}
+	}
+	return merged
+}
+
+// mergedIn creates the In condition of the values. It returns nil if the condition was already created.
+func (o *optimizer) mergedIn(field string, values []any) (filter.Condition, bool) {
+	if values == nil {
+		return nil, true
+	}
+	unique := uniqueValues(values)
+	list := typedList(unique)
+	if _, untyped := list.([]any); untyped {
+		return nil, false
+	}
+	if o.options.MaxInValues > 0 && len(unique) > o.options.MaxInValues {
+		return nil, false
+	}
+	return filter.In(field, list), true
+}
+
+// equalsValues returns the field and the values of an Equals or In condition with non-NULL scalar values.
+func equalsValues(condition filter.Condition) (string, []any, bool) {
+	var field string
+	var value any
+	switch c := condition.(type) {
+	case *filter.EqualsCondition:
+		field, value = c.Field, c.Value
+	case *filter.InCondition:
+		field, value = c.Field, c.Value
+	default:
+		return "", nil, false
+	}
+	var values []any
+	if isListType(value) {
+		valVal := reflect.ValueOf(value)
+		for i := 0; i < valVal.Len(); i++ {
+			values = append(values, valVal.Index(i).Interface())
+		}
+	} else {
+		values = []any{value}
+	}
+	if len(values) == 0 {
+		return "", nil, false
+	}
+	for _, v := range values {
+		if !isScalarValue(v) {
+			return "", nil, false
+		}
+	}
+	return field, values, true
+}
+
+// isScalarValue reports whether the value is compared as a single value, unlike NULL, lists and JSON objects.
+func isScalarValue(value any) bool {
+	t := reflect.TypeOf(value)
+	if t == nil {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.Array, reflect.Func, reflect.Chan:
+		return false
+	case reflect.Struct:
+		return isValueStruct(t)
+	}
+	return true
+}
+
+// isConstantFalse reports whether the condition is rendered as a constant false, like an In condition without
+// values or array conditions without values.
+func isConstantFalse(condition filter.Condition) bool {
+	var value any
+	switch c := condition.(type) {
+	case *filter.InCondition:
+		value = c.Value
+	case *filter.ArrayContainsArrayCondition:
+		value = c.Value
+	case *filter.ArrayIsContainedCondition:
+		value = c.Value
+	case *filter.OverlapsCondition:
+		value = c.Value
+	case *filter.ArraysOverlapCondition:
+		value = c.Value
+	default:
+		return false
+	}
+	return value == nil || (isListType(value) && reflect.ValueOf(value).Len() == 0)
+}
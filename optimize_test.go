@@ -0,0 +1,192 @@
+package filtersquirrel
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/require"
+	"github.com/xafelium/filter"
+	"math/rand"
+	"testing"
+)
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestOptimize(t *testing.T) {
+	tests := []struct {
+		name         string
+		filter       filter.Condition
+		expected     filter.Condition
+		expectedSql  string
+		expectedArgs []any
+	}{
+		{
+			name:         "nested conjunctions",
+			filter:       filter.Where(filter.And(filter.And(filter.Equals("a", 1), filter.Equals("b", 2)), filter.Group(filter.And(filter.Equals("c", 3), filter.Equals("d", 4))))),
+			expected:     filter.Where(filter.And(filter.Equals("a", 1), filter.Equals("b", 2), filter.Equals("c", 3), filter.Equals("d", 4))),
+			expectedSql:  "SELECT * FROM t WHERE (a = $1 AND b = $2 AND c = $3 AND d = $4)",
+			expectedArgs: []any{1, 2, 3, 4},
+		},
+		{
+			name:         "groups and double negations",
+			filter:       filter.Where(filter.Group(filter.Group(filter.Not(filter.Group(filter.Not(filter.Equals("a", 1))))))),
+			expected:     filter.Where(filter.Equals("a", 1)),
+			expectedSql:  "SELECT * FROM t WHERE a = $1",
+			expectedArgs: []any{1},
+		},
+		{
+			name:         "duplicates",
+			filter:       filter.Where(filter.And(filter.Equals("a", 1), filter.Or(filter.IsNil("b"), filter.IsNil("b")), filter.Equals("a", 1))),
+			expected:     filter.Where(filter.And(filter.Equals("a", 1), filter.IsNil("b"))),
+			expectedSql:  "SELECT * FROM t WHERE (a = $1 AND b IS NULL)",
+			expectedArgs: []any{1},
+		},
+		{
+			name: "or of equals",
+			filter: filter.Where(filter.Or(
+				filter.Equals("a", 1),
+				filter.Equals("b", "x"),
+				filter.Or(filter.In("a", []int{2, 3}), filter.Equals("a", 1)),
+				filter.Equals("c", nil),
+				filter.Equals("c", 2),
+			)),
+			expected: filter.Where(filter.Or(
+				filter.In("a", []int{1, 2, 3}),
+				filter.Equals("b", "x"),
+				filter.Equals("c", nil),
+				filter.Equals("c", 2),
+			)),
+			expectedSql:  "SELECT * FROM t WHERE (a IN ($1,$2,$3) OR b = $4 OR c IS NULL OR c = $5)",
+			expectedArgs: []any{1, 2, 3, "x", 2},
+		},
+		{
+			name:         "or of equals with different types",
+			filter:       filter.Where(filter.Or(filter.Equals("a", 1), filter.Equals("a", "1"))),
+			expected:     filter.Where(filter.Or(filter.Equals("a", 1), filter.Equals("a", "1"))),
+			expectedSql:  "SELECT * FROM t WHERE (a = $1 OR a = $2)",
+			expectedArgs: []any{1, "1"},
+		},
+		{
+			name:         "constant false in or",
+			filter:       filter.Where(filter.Or(filter.Overlaps("tags", []string{}), filter.Equals("a", 1))),
+			expected:     filter.Where(filter.Equals("a", 1)),
+			expectedSql:  "SELECT * FROM t WHERE a = $1",
+			expectedArgs: []any{1},
+		},
+		{
+			name:        "constant false in and",
+			filter:      filter.Where(filter.And(filter.Equals("a", 1), filter.Group(filter.In("b", []int{})))),
+			expected:    filter.Where(filter.In("b", []int{})),
+			expectedSql: "SELECT * FROM t WHERE (1=0)",
+		},
+		{
+			name:         "negated constant false",
+			filter:       filter.Where(filter.And(filter.Not(filter.ArraysOverlap("tags", nil)), filter.Equals("a", 1))),
+			expected:     filter.Where(filter.Equals("a", 1)),
+			expectedSql:  "SELECT * FROM t WHERE a = $1",
+			expectedArgs: []any{1},
+		},
+		{
+			name:        "constant true",
+			filter:      filter.Where(filter.Or(filter.Not(filter.ArrayIsContained("tags", nil)), filter.Equals("a", 1))),
+			expected:    filter.Where(nil),
+			expectedSql: "SELECT * FROM t",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, optimize(test.filter, DefaultOptions()))
+
+			builder, _, err := ApplyFilter(sq.Select("*").From("t").PlaceholderFormat(sq.Dollar), test.filter, WithOptimize(true))
+			require.NoError(t, err)
+			sql, args, err := builder.ToSql()
+			require.NoError(t, err)
+			require.Equal(t, test.expectedSql, sql)
+			require.Equal(t, test.expectedArgs, args)
+		})
+	}
+}
+
+func TestOptimizeKeepsMaxInValues(t *testing.T) {
+	options := FromDefaultOptions(WithMaxInValues(2))
+	condition := filter.Or(filter.Equals("a", 1), filter.In("a", []int{2, 3}))
+	require.Equal(t, condition, optimize(condition, options))
+}
+
+func TestOptimizeKeepsChecks(t *testing.T) {
+	m, err := NewStructMapper(struct {
+		ID int `filter:"id"`
+	}{})
+	require.NoError(t, err)
+	_, _, err = ApplyFilter(sq.Select("*").From("users"),
+		filter.Where(filter.And(filter.In("id", []int{}), filter.Equals("secret", 1))),
+		WithMapperFunc(m.Map), WithOptimize(true))
+	require.ErrorIs(t, err, ErrUnknownField)
+
+	var condition filter.Condition = filter.Equals("id", 1)
+	for i := 0; i < 10; i++ {
+		condition = filter.Group(condition)
+	}
+	_, _, err = ApplyFilter(sq.Select("*").From("users"), filter.Where(condition), WithMaxDepth(3), WithOptimize(true))
+	var limitErr *LimitError
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, LimitDepth, limitErr.Limit)
+}
+
+// TestOptimizeEquivalence evaluates random filters and their optimized form against the rows of TestEvaluate,
+// which must have the same result in SQL's three-valued logic, including unknown. With null-aware negation NOT
+// only depends on whether its operand is true, so only the rows matched must be the same.
+func TestOptimizeEquivalence(t *testing.T) {
+	leaves := []filter.Condition{
+		filter.Equals("status", "active"),
+		filter.Equals("status", "archived"),
+		filter.NotEquals("status", "active"),
+		filter.In("status", []string{}),
+		filter.In("age", []int{18, 45}),
+		filter.Equals("age", 30),
+		filter.IsNil("status"),
+		filter.GreaterThan("age", 20),
+		filter.Contains("name", "a"),
+		filter.ArrayContains("tags", "a"),
+		filter.Overlaps("tags", nil),
+		filter.ArrayContainsArray("tags", []string{}),
+	}
+	random := rand.New(rand.NewSource(1))
+	var generate func(depth int) filter.Condition
+	generate = func(depth int) filter.Condition {
+		if depth == 0 || random.Intn(3) == 0 {
+			return leaves[random.Intn(len(leaves))]
+		}
+		switch random.Intn(4) {
+		case 0:
+			return filter.Not(generate(depth - 1))
+		case 1:
+			return filter.Group(generate(depth - 1))
+		}
+		conditions := make([]filter.Condition, 2+random.Intn(3))
+		for i := range conditions {
+			conditions[i] = generate(depth - 1)
+		}
+		if random.Intn(2) == 0 {
+			return filter.And(conditions...)
+		}
+		return filter.Or(conditions...)
+	}
+
+	for _, nullAware := range []bool{false, true} {
+		options := FromDefaultOptions(WithNullAwareNegation(nullAware))
+		for i := 0; i < 500; i++ {
+			condition := filter.Where(generate(5))
+			optimized := optimize(condition, options)
+			for _, row := range evaluateRows {
+				e := &evaluator{accessor: MapAccessor(row), options: options}
+				expected, err := e.eval(condition)
+				require.NoError(t, err)
+				actual, err := e.eval(optimized)
+				require.NoError(t, err)
+				if nullAware {
+					expected, actual = triOf(expected == triTrue), triOf(actual == triTrue)
+				}
+				require.Equal(t, expected, actual, "%s optimized to %s for row %v", condition, optimized, row["id"])
+			}
+		}
+	}
+}
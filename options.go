@@ -9,13 +9,75 @@ func FieldAsIsMapperFunc(fieldName string) (string, error) {
 
 type Options struct {
 	MapperFunc FieldMapperFunc
+	Dialect    Dialect
+	// ConditionBuilders override the globally registered builders for a single call.
+	ConditionBuilders map[string]ConditionBuilderFunc
+	// CaseSensitivity configures the string comparison per domain field name.
+	CaseSensitivity map[string]CaseSensitivity
+	// AllowedConditions restricts the condition types per domain field name. Fields without entry are unrestricted.
+	AllowedConditions map[string]map[string]bool
+	// MaxDepth limits the nesting of conditions. Zero means unlimited.
+	MaxDepth int
+	// MaxConditions limits the number of comparison conditions. Zero means unlimited.
+	MaxConditions int
+	// MaxInValues limits the number of values of an InCondition. Zero means unlimited.
+	MaxInValues int
+	// MaxArrayValues limits the number of values of the array conditions. Zero means unlimited.
+	MaxArrayValues int
+	// RegexAllowed allows Regex and NotRegex conditions. Defaults to true.
+	RegexAllowed bool
+	// Joins are added by ApplyFilter for the table aliases referenced by the filter.
+	Joins []Join
+	// DefaultPageSize is used by ApplyPage if no page size is requested.
+	DefaultPageSize uint64
+	// MaxPageSize limits the page size of ApplyPage. Zero means unlimited.
+	MaxPageSize uint64
+	// ColumnMapperFunc maps the columns parsed by ParseWhere back to field names.
+	ColumnMapperFunc ColumnMapperFunc
+	// NullAwareNegation makes NotEquals and Not match NULL values instead of treating them as unknown.
+	NullAwareNegation bool
+	// JSONFields are the domain fields of JSON columns, whose nested values are filtered with dotted field names.
+	JSONFields map[string]bool
+	// FullTextSearch are the languages of the domain fields whose Contains conditions are full-text searches.
+	FullTextSearch map[string]string
+	// SearchLanguage is the language of SearchCondition on fields without entry in FullTextSearch.
+	// Empty means the default language of the database.
+	SearchLanguage string
+	// FieldTypes coerce the values compared with the domain fields.
+	FieldTypes map[string]FieldType
+	// Optimize normalizes the filter before it is translated.
+	Optimize bool
+	// ArrayParameterThreshold is the number of values from which lists are bound as a single array parameter on
+	// dialects supporting it. Zero disables array parameters.
+	ArrayParameterThreshold int
+	// ArrayParameterFunc converts the lists bound as array parameter, e.g. pq.Array for lib/pq.
+	ArrayParameterFunc func(list any) any
+	// TypedArrayParameters binds the values of the array conditions as a single array parameter.
+	TypedArrayParameters bool
+	// TypedArrayParameterFunc converts the values of the array conditions bound as array parameter.
+	TypedArrayParameterFunc func(list any) any
+	// ArrayElementTypes are the SQL types of the array elements per domain field, e.g. "uuid".
+	ArrayElementTypes map[string]string
+	// RangeTypes are the PostgreSQL range types per domain field, e.g. "daterange".
+	RangeTypes map[string]string
+	// Periods are the domain fields of ranges stored in two columns.
+	Periods map[string]Period
+	// JSONArrayFields are the domain fields of arrays stored in JSON columns.
+	JSONArrayFields map[string]bool
+	// ArrayTables are the child tables of the domain fields of arrays stored in rows.
+	ArrayTables map[string]ArrayTable
 }
 
 type Option func(o *Options)
 
 func DefaultOptions() *Options {
 	return &Options{
-		MapperFunc: FieldAsIsMapperFunc,
+		MapperFunc:       FieldAsIsMapperFunc,
+		Dialect:          Postgres,
+		RegexAllowed:     true,
+		DefaultPageSize:  DefaultPageSize,
+		MaxPageSize:      DefaultMaxPageSize,
+		ColumnMapperFunc: ColumnAsIsMapperFunc,
 	}
 }
 
@@ -35,3 +97,288 @@ func WithMapperFunc(f FieldMapperFunc) Option {
 		o.MapperFunc = f
 	}
 }
+
+// WithDialect selects the SQL dialect used to render the conditions. Defaults to Postgres.
+func WithDialect(d Dialect) Option {
+	return func(o *Options) {
+		if d == nil {
+			return
+		}
+		o.Dialect = d
+	}
+}
+
+// WithConditionBuilder registers the builder for the condition type for a single call.
+func WithConditionBuilder(conditionType string, b ConditionBuilderFunc) Option {
+	return func(o *Options) {
+		if b == nil {
+			return
+		}
+		if o.ConditionBuilders == nil {
+			o.ConditionBuilders = make(map[string]ConditionBuilderFunc)
+		}
+		o.ConditionBuilders[conditionType] = b
+	}
+}
+
+// WithCaseSensitivity configures how the string values of the domain fields are compared.
+func WithCaseSensitivity(cs CaseSensitivity, fields ...string) Option {
+	return func(o *Options) {
+		if o.CaseSensitivity == nil {
+			o.CaseSensitivity = make(map[string]CaseSensitivity)
+		}
+		for _, field := range fields {
+			o.CaseSensitivity[field] = cs
+		}
+	}
+}
+
+// WithAllowedConditions restricts the condition types which may be used with the domain field. The restriction of
+// a JSON field applies to the paths into it without a restriction of their own.
+func WithAllowedConditions(field string, conditionTypes ...string) Option {
+	return func(o *Options) {
+		if o.AllowedConditions == nil {
+			o.AllowedConditions = make(map[string]map[string]bool)
+		}
+		allowed := make(map[string]bool)
+		for _, t := range conditionTypes {
+			allowed[t] = true
+		}
+		o.AllowedConditions[field] = allowed
+	}
+}
+
+// WithMaxDepth limits the nesting depth of the conditions, not counting the WHERE condition.
+func WithMaxDepth(n int) Option {
+	return func(o *Options) {
+		o.MaxDepth = n
+	}
+}
+
+// WithMaxConditions limits the number of comparison conditions.
+func WithMaxConditions(n int) Option {
+	return func(o *Options) {
+		o.MaxConditions = n
+	}
+}
+
+// WithMaxInValues limits the number of values of an InCondition.
+func WithMaxInValues(n int) Option {
+	return func(o *Options) {
+		o.MaxInValues = n
+	}
+}
+
+// WithMaxArrayValues limits the number of values of the ArrayContainsArray, ArrayIsContained and Overlaps conditions.
+func WithMaxArrayValues(n int) Option {
+	return func(o *Options) {
+		o.MaxArrayValues = n
+	}
+}
+
+// WithRegexAllowed allows or rejects Regex and NotRegex conditions.
+func WithRegexAllowed(allowed bool) Option {
+	return func(o *Options) {
+		o.RegexAllowed = allowed
+	}
+}
+
+// WithJoin registers the join for the table alias, which ApplyFilter adds if the filter references the alias.
+func WithJoin(j Join) Option {
+	return func(o *Options) {
+		for i, existing := range o.Joins {
+			if existing.Alias == j.Alias {
+				o.Joins[i] = j
+				return
+			}
+		}
+		o.Joins = append(o.Joins, j)
+	}
+}
+
+// WithDefaultPageSize sets the page size used by ApplyPage if no page size is requested.
+func WithDefaultPageSize(size uint64) Option {
+	return func(o *Options) {
+		o.DefaultPageSize = size
+	}
+}
+
+// WithMaxPageSize limits the page size of ApplyPage. Zero means unlimited.
+func WithMaxPageSize(size uint64) Option {
+	return func(o *Options) {
+		o.MaxPageSize = size
+	}
+}
+
+// WithColumnMapperFunc sets the function used by ParseWhere to map columns back to field names.
+func WithColumnMapperFunc(f ColumnMapperFunc) Option {
+	return func(o *Options) {
+		if f == nil {
+			return
+		}
+		o.ColumnMapperFunc = f
+	}
+}
+
+// WithNullAwareNegation makes NotEquals and Not match rows with NULL values, e.g. "status != archived" matches rows
+// without status. NotEquals is rendered as IS DISTINCT FROM and NOT as IS NOT TRUE or their dialect equivalents.
+func WithNullAwareNegation(enabled bool) Option {
+	return func(o *Options) {
+		o.NullAwareNegation = enabled
+	}
+}
+
+// WithJSONField declares JSON columns, whose nested values are filtered with dotted field names. The column of the
+// field is mapped by the FieldMapperFunc, e.g. "attributes.color" compares the color of the attributes column.
+// Numeric keys index arrays. Objects compared with Equals match if the JSON column contains them.
+func WithJSONField(fields ...string) Option {
+	return func(o *Options) {
+		if o.JSONFields == nil {
+			o.JSONFields = make(map[string]bool)
+		}
+		for _, field := range fields {
+			o.JSONFields[field] = true
+		}
+	}
+}
+
+// WithFullTextSearch renders ContainsCondition and SearchCondition on the domain fields as full-text search in the
+// language, e.g. "english" for PostgreSQL. An empty language uses the default one of the database.
+func WithFullTextSearch(language string, fields ...string) Option {
+	return func(o *Options) {
+		if o.FullTextSearch == nil {
+			o.FullTextSearch = make(map[string]string)
+		}
+		for _, field := range fields {
+			o.FullTextSearch[field] = language
+		}
+	}
+}
+
+// WithSearchLanguage sets the language of SearchCondition on fields not configured with WithFullTextSearch.
+func WithSearchLanguage(language string) Option {
+	return func(o *Options) {
+		o.SearchLanguage = language
+	}
+}
+
+// WithFieldType coerces the values compared with the domain fields to the type of their columns.
+// Invalid values are reported as violations by Validate and ApplyFilter. The type of a JSON field applies to the
+// paths into it without a type of their own.
+func WithFieldType(t FieldType, fields ...string) Option {
+	return func(o *Options) {
+		if t.Coerce == nil {
+			return
+		}
+		if o.FieldTypes == nil {
+			o.FieldTypes = make(map[string]FieldType)
+		}
+		for _, field := range fields {
+			o.FieldTypes[field] = t
+		}
+	}
+}
+
+// WithFieldTypes coerces the values of several domain fields, e.g. the ones of StructMapper.FieldTypes.
+func WithFieldTypes(types map[string]FieldType) Option {
+	return func(o *Options) {
+		for field, t := range types {
+			WithFieldType(t, field)(o)
+		}
+	}
+}
+
+// WithOptimize normalizes the filter before it is translated: nested conjunctions are flattened, groups and double
+// negations removed, identical conditions deduplicated, Equals conditions on the same field in an OR merged into
+// an In condition and conditions which are constantly false, like an Overlaps condition without values, folded.
+// The filter is validated and translated once before it is optimized, so the limits are enforced and the paths of
+// a ConditionError refer to the original filter. Condition builders, including custom ones, therefore run twice.
+func WithOptimize(enabled bool) Option {
+	return func(o *Options) {
+		o.Optimize = enabled
+	}
+}
+
+// WithArrayParameters binds lists with at least threshold values as a single array parameter on PostgreSQL,
+// e.g. id = ANY ($1) instead of id IN ($1,$2,$3), to keep the statement cache effective. The function converts the
+// lists for the driver, e.g. pq.Array for lib/pq, and may be nil for drivers binding slices as arrays like pgx.
+func WithArrayParameters(threshold int, f func(list any) any) Option {
+	return func(o *Options) {
+		o.ArrayParameterThreshold = threshold
+		o.ArrayParameterFunc = f
+	}
+}
+
+// WithTypedArrayParameters binds the values of ArrayContainsArray, ArrayIsContained and Overlaps conditions as a
+// single parameter cast to the array type of the column on PostgreSQL, e.g. tags @> $1::text[] instead of
+// tags @> ARRAY[$1,$2]. The element type is taken from WithArrayElementType, the FieldType of the field or the Go
+// type of the values. Values of unknown type are bound one by one. The function converts the lists for the driver
+// like the one of WithArrayParameters, which is configured separately.
+func WithTypedArrayParameters(f func(list any) any) Option {
+	return func(o *Options) {
+		o.TypedArrayParameters = true
+		o.TypedArrayParameterFunc = f
+	}
+}
+
+// WithArrayElementType sets the SQL type of the array elements of the fields for WithTypedArrayParameters.
+func WithArrayElementType(sqlType string, fields ...string) Option {
+	return func(o *Options) {
+		if o.ArrayElementTypes == nil {
+			o.ArrayElementTypes = make(map[string]string)
+		}
+		for _, field := range fields {
+			o.ArrayElementTypes[field] = sqlType
+		}
+	}
+}
+
+// WithRangeType sets the PostgreSQL range type of the fields, e.g. "daterange". Without it, the range type is derived
+// from the values, e.g. tstzrange for times and int8range for integers.
+func WithRangeType(rangeType string, fields ...string) Option {
+	return func(o *Options) {
+		if o.RangeTypes == nil {
+			o.RangeTypes = make(map[string]string)
+		}
+		for _, field := range fields {
+			o.RangeTypes[field] = rangeType
+		}
+	}
+}
+
+// WithPeriod declares the field as a period stored in the columns of the start and end fields, e.g. the validity of
+// a contract in valid_from and valid_to. OverlapsCondition with a Range and RangeContainsCondition on the field
+// compare the columns on all dialects, e.g. valid_from < $1 AND valid_to > $2. The end is excluded.
+func WithPeriod(field string, startField string, endField string) Option {
+	return func(o *Options) {
+		if o.Periods == nil {
+			o.Periods = make(map[string]Period)
+		}
+		o.Periods[field] = Period{Start: startField, End: endField}
+	}
+}
+
+// WithJSONArray declares the fields as arrays stored in JSON columns, e.g. ["a","b"]. The array conditions on the
+// fields are rendered with the JSON functions of the dialect, e.g. JSON_CONTAINS on MySQL or json_each on SQLite.
+func WithJSONArray(fields ...string) Option {
+	return func(o *Options) {
+		if o.JSONArrayFields == nil {
+			o.JSONArrayFields = make(map[string]bool)
+		}
+		for _, field := range fields {
+			o.JSONArrayFields[field] = true
+		}
+	}
+}
+
+// WithArrayTable declares the field as an array stored in the rows of a child table. The array conditions on the
+// field are rendered as subqueries on the table, e.g. EXISTS (SELECT 1 FROM post_tags t WHERE t.post_id = p.id AND
+// t.tag = ?), on all dialects.
+func WithArrayTable(field string, table ArrayTable) Option {
+	return func(o *Options) {
+		if o.ArrayTables == nil {
+			o.ArrayTables = make(map[string]ArrayTable)
+		}
+		o.ArrayTables[field] = table
+	}
+}
@@ -0,0 +1,96 @@
+package filtersquirrel
+
+import (
+	"fmt"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/xafelium/filter"
+	"math"
+)
+
+// Page size defaults used by DefaultOptions.
+const (
+	DefaultPageSize    = 20
+	DefaultMaxPageSize = 100
+)
+
+// PageRequest requests a page of an offset pagination.
+type PageRequest struct {
+	// Page is the 1-based page number. Defaults to the first page.
+	Page uint64
+	// Size is the number of rows per page. Defaults to the configured default page size and is
+	// limited to the configured maximum page size.
+	Size uint64
+	Sort []SortField
+}
+
+// PageQuery contains the queries for a page and the total number of rows.
+type PageQuery struct {
+	// Query selects the rows of the page.
+	Query sq.SelectBuilder
+	// CountQuery counts all rows matching the filter.
+	CountQuery sq.SelectBuilder
+	// Page is the effective 1-based page number.
+	Page uint64
+	// Size is the effective page size.
+	Size uint64
+	// TableAliases are the table aliases referenced by the filter and the sort fields.
+	TableAliases []string
+}
+
+// ApplyPage applies the filter to the base query and returns the query for the requested page together with
+// the query counting all matching rows. The count query counts the rows of the filtered base query in a subquery,
+// i.e. SELECT COUNT(*) FROM (...) AS count_query, so GROUP BY and DISTINCT are counted correctly. The base query
+// must not be ordered, limited or have suffixes itself.
+func ApplyPage(b sq.SelectBuilder, condition filter.Condition, page PageRequest, opts ...Option) (*PageQuery, error) {
+	options := FromDefaultOptions(opts...)
+	sqlizer, ctx, err := buildFilter(condition, options)
+	if err != nil {
+		return nil, err
+	}
+	orderBys, err := ctx.applySort(page.Sort)
+	if err != nil {
+		return nil, err
+	}
+	b, err = ctx.applyJoins(b)
+	if err != nil {
+		return nil, err
+	}
+	if sqlizer != nil {
+		b = b.Where(sqlizer)
+	}
+
+	number, size := page.Page, page.Size
+	if number == 0 {
+		number = 1
+	}
+	if size == 0 {
+		size = options.DefaultPageSize
+	}
+	if options.MaxPageSize > 0 && size > options.MaxPageSize {
+		size = options.MaxPageSize
+	}
+	if size == 0 {
+		return nil, fmt.Errorf("page size must be greater than zero")
+	}
+	if number-1 > math.MaxUint64/size {
+		return nil, fmt.Errorf("page %d is out of range", number)
+	}
+
+	return &PageQuery{
+		Query:        ctx.Dialect().Page(b, orderBys, size, (number-1)*size),
+		CountQuery:   countQuery(b),
+		Page:         number,
+		Size:         size,
+		TableAliases: ctx.tableAliasList(),
+	}, nil
+}
+
+// countQuery wraps the query into SELECT COUNT(*) FROM (...). Prefixes like WITH clauses stay outside the subquery.
+func countQuery(b sq.SelectBuilder) sq.SelectBuilder {
+	return b.Prefix("SELECT COUNT(*) FROM (").Suffix(") AS count_query")
+}
+
+// pageLimitOffset selects the page with LIMIT and OFFSET.
+func pageLimitOffset(b sq.SelectBuilder, orderBys []string, limit uint64, offset uint64) sq.SelectBuilder {
+	return b.OrderBy(orderBys...).Limit(limit).Offset(offset)
+}
@@ -0,0 +1,132 @@
+package filtersquirrel
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/require"
+	"github.com/xafelium/filter"
+	"math"
+	"testing"
+)
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestApplyPage(t *testing.T) {
+	base := sq.Select("u.id", "u.name").From("users u").PlaceholderFormat(sq.Dollar)
+	condition := filter.Where(filter.Equals("city", "Berlin"))
+	opts := []Option{
+		WithMapperFunc(func(fieldName string) (string, error) {
+			if fieldName == "city" {
+				return "a.city", nil
+			}
+			return "u." + fieldName, nil
+		}),
+		WithJoin(Join{Alias: "a", Table: "addresses a", On: "a.id = u.address_id"}),
+	}
+
+	tests := []struct {
+		name          string
+		page          PageRequest
+		opts          []Option
+		expectedSql   string
+		expectedPage  uint64
+		expectedSize  uint64
+		expectedAlias []string
+		expectedError string
+	}{
+		{
+			name:         "defaults",
+			expectedSql:  "SELECT u.id, u.name FROM users u JOIN addresses a ON a.id = u.address_id WHERE a.city = $1 LIMIT 20 OFFSET 0",
+			expectedPage: 1,
+			expectedSize: 20,
+		},
+		{
+			name:          "page with sort",
+			page:          PageRequest{Page: 3, Size: 10, Sort: []SortField{{Field: "name"}}},
+			expectedSql:   "SELECT u.id, u.name FROM users u JOIN addresses a ON a.id = u.address_id WHERE a.city = $1 ORDER BY u.name ASC LIMIT 10 OFFSET 20",
+			expectedPage:  3,
+			expectedSize:  10,
+			expectedAlias: []string{"a", "u"},
+		},
+		{
+			name:         "maximum page size",
+			page:         PageRequest{Page: 2, Size: 1000},
+			expectedSql:  "SELECT u.id, u.name FROM users u JOIN addresses a ON a.id = u.address_id WHERE a.city = $1 LIMIT 100 OFFSET 100",
+			expectedPage: 2,
+			expectedSize: 100,
+		},
+		{
+			name:         "configured page sizes",
+			page:         PageRequest{},
+			opts:         []Option{WithDefaultPageSize(50), WithMaxPageSize(25)},
+			expectedSql:  "SELECT u.id, u.name FROM users u JOIN addresses a ON a.id = u.address_id WHERE a.city = $1 LIMIT 25 OFFSET 0",
+			expectedPage: 1,
+			expectedSize: 25,
+		},
+		{
+			name:          "sqlserver page with sort",
+			page:          PageRequest{Page: 3, Size: 10, Sort: []SortField{{Field: "name"}}},
+			opts:          []Option{WithDialect(SQLServer)},
+			expectedSql:   "SELECT u.id, u.name FROM users u JOIN addresses a ON a.id = u.address_id WHERE a.city = $1 ORDER BY u.name ASC OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY",
+			expectedPage:  3,
+			expectedSize:  10,
+			expectedAlias: []string{"a", "u"},
+		},
+		{
+			name:         "sqlserver page without sort",
+			opts:         []Option{WithDialect(SQLServer)},
+			expectedSql:  "SELECT u.id, u.name FROM users u JOIN addresses a ON a.id = u.address_id WHERE a.city = $1 ORDER BY (SELECT NULL) OFFSET 0 ROWS FETCH NEXT 20 ROWS ONLY",
+			expectedPage: 1,
+			expectedSize: 20,
+		},
+		{
+			name:          "page out of range",
+			page:          PageRequest{Page: math.MaxUint64, Size: 10},
+			expectedError: "page 18446744073709551615 is out of range",
+		},
+		{
+			name:          "zero page size",
+			opts:          []Option{WithDefaultPageSize(0)},
+			expectedError: "page size must be greater than zero",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			page, err := ApplyPage(base, condition, test.page, append(append([]Option{}, opts...), test.opts...)...)
+
+			if test.expectedError != "" {
+				require.EqualError(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expectedPage, page.Page)
+			require.Equal(t, test.expectedSize, page.Size)
+			expectedAlias := test.expectedAlias
+			if expectedAlias == nil {
+				expectedAlias = []string{"a"}
+			}
+			require.Equal(t, expectedAlias, page.TableAliases)
+
+			sql, args, err := page.Query.ToSql()
+			require.NoError(t, err)
+			require.Equal(t, test.expectedSql, sql)
+			require.Equal(t, []any{"Berlin"}, args)
+
+			sql, args, err = page.CountQuery.ToSql()
+			require.NoError(t, err)
+			require.Equal(t, "SELECT COUNT(*) FROM ( SELECT u.id, u.name FROM users u JOIN addresses a ON a.id = u.address_id WHERE a.city = $1 ) AS count_query", sql)
+			require.Equal(t, []any{"Berlin"}, args)
+		})
+	}
+}
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestApplyPageCountsGroups(t *testing.T) {
+	base := sq.Select("u.city", "COUNT(*)").From("users u").GroupBy("u.city").Prefix("WITH x AS (SELECT 1)")
+	page, err := ApplyPage(base, filter.Where(filter.Equals("u.active", true)), PageRequest{})
+	require.NoError(t, err)
+
+	sql, args, err := page.CountQuery.ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "WITH x AS (SELECT 1) SELECT COUNT(*) FROM ( SELECT u.city, COUNT(*) FROM users u WHERE u.active = ? GROUP BY u.city ) AS count_query", sql)
+	require.Equal(t, []any{true}, args)
+}
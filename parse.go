@@ -0,0 +1,494 @@
+package filtersquirrel
+
+import (
+	"errors"
+	"fmt"
+	"github.com/xafelium/filter"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ColumnMapperFunc is a function to map database table columns back to domain object field names.
+type ColumnMapperFunc func(column string) (string, error)
+
+func ColumnAsIsMapperFunc(column string) (string, error) {
+	return column, nil
+}
+
+// ParseWhere parses a SQL WHERE clause into the equivalent filter.Condition. It understands the subset of SQL
+// emitted by ApplyFilter for PostgreSQL: comparisons, IN, IS [NOT] NULL, ILIKE, ~, !~, = ANY, @>, <@, &&,
+// AND, OR, NOT and parentheses. Values are either literals or placeholders (?, $1 or @p1) bound to args.
+// The columns are mapped to field names with the ColumnMapperFunc configured by WithColumnMapperFunc.
+func ParseWhere(sql string, args []any, opts ...Option) (filter.Condition, error) {
+	tokens, err := tokenizeSql(sql)
+	if err != nil {
+		return nil, err
+	}
+	p := &whereParser{
+		tokens:  tokens,
+		args:    args,
+		options: FromDefaultOptions(opts...),
+	}
+	if p.peekKeyword("WHERE") {
+		p.pos++
+	}
+	if p.done() {
+		return filter.Where(nil), nil
+	}
+	condition, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("unexpected token at position %d: %s", p.peek().pos, p.peek().text)
+	}
+	return filter.Where(condition), nil
+}
+
+type sqlTokenKind int
+
+const (
+	identToken sqlTokenKind = iota
+	stringToken
+	numberToken
+	placeholderToken
+	symbolToken
+)
+
+type sqlToken struct {
+	kind sqlTokenKind
+	text string
+	pos  int
+}
+
+// sqlSymbols are the operators and punctuation, longest first.
+var sqlSymbols = []string{"<>", "!=", "<=", ">=", "!~", "@>", "<@", "&&", "=", "<", ">", "~", "(", ")", "[", "]", ","}
+
+func tokenizeSql(sql string) ([]sqlToken, error) {
+	var tokens []sqlToken
+	runes := []rune(sql)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '\'':
+			var b strings.Builder
+			j := i + 1
+			for {
+				if j >= len(runes) {
+					return nil, fmt.Errorf("unterminated string at position %d", i)
+				}
+				if runes[j] == '\'' {
+					if j+1 < len(runes) && runes[j+1] == '\'' {
+						b.WriteRune('\'')
+						j += 2
+						continue
+					}
+					break
+				}
+				b.WriteRune(runes[j])
+				j++
+			}
+			tokens = append(tokens, sqlToken{kind: stringToken, text: b.String(), pos: i})
+			i = j + 1
+		case r == '?':
+			tokens = append(tokens, sqlToken{kind: placeholderToken, text: "?", pos: i})
+			i++
+		case r == '$' || (r == '@' && i+1 < len(runes) && (runes[i+1] == 'p' || runes[i+1] == 'P')):
+			j := i + 1
+			if r == '@' {
+				j++
+			}
+			start := j
+			for j < len(runes) && unicode.IsDigit(runes[j]) {
+				j++
+			}
+			if j == start {
+				return nil, fmt.Errorf("invalid placeholder at position %d", i)
+			}
+			tokens = append(tokens, sqlToken{kind: placeholderToken, text: string(runes[i:j]), pos: i})
+			i = j
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			j := i + 1
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, sqlToken{kind: numberToken, text: string(runes[i:j]), pos: i})
+			i = j
+		case unicode.IsLetter(r) || r == '_' || r == '"':
+			j := i
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || strings.ContainsRune(`_."`, runes[j])) {
+				j++
+			}
+			tokens = append(tokens, sqlToken{kind: identToken, text: string(runes[i:j]), pos: i})
+			i = j
+		default:
+			matched := false
+			for _, symbol := range sqlSymbols {
+				if strings.HasPrefix(string(runes[i:]), symbol) {
+					tokens = append(tokens, sqlToken{kind: symbolToken, text: symbol, pos: i})
+					i += len([]rune(symbol))
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character at position %d: %c", i, r)
+			}
+		}
+	}
+	return tokens, nil
+}
+
+type whereParser struct {
+	tokens []sqlToken
+	pos    int
+	args   []any
+	// nextArg is the index of the argument bound to the next "?" placeholder.
+	nextArg int
+	options *Options
+}
+
+func (p *whereParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *whereParser) peek() sqlToken {
+	if p.done() {
+		return sqlToken{pos: -1, text: "end of input"}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *whereParser) peekKeyword(keyword string) bool {
+	t := p.peek()
+	return !p.done() && t.kind == identToken && strings.EqualFold(t.text, keyword)
+}
+
+func (p *whereParser) peekSymbol(symbol string) bool {
+	t := p.peek()
+	return !p.done() && t.kind == symbolToken && t.text == symbol
+}
+
+func (p *whereParser) expectKeyword(keyword string) error {
+	if !p.peekKeyword(keyword) {
+		return p.unexpected(keyword)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *whereParser) expectSymbol(symbol string) error {
+	if !p.peekSymbol(symbol) {
+		return p.unexpected(symbol)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *whereParser) unexpected(expected string) error {
+	t := p.peek()
+	return fmt.Errorf("expected %s at position %d but got %s", expected, t.pos, t.text)
+}
+
+func (p *whereParser) parseOr() (filter.Condition, error) {
+	return p.parseConjunction("OR", p.parseAnd, filter.Or)
+}
+
+func (p *whereParser) parseAnd() (filter.Condition, error) {
+	return p.parseConjunction("AND", p.parseNot, filter.And)
+}
+
+func (p *whereParser) parseConjunction(keyword string, parseOperand func() (filter.Condition, error), conj func(c ...filter.Condition) filter.Condition) (filter.Condition, error) {
+	first, err := parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	conditions := []filter.Condition{first}
+	for p.peekKeyword(keyword) {
+		p.pos++
+		c, err := parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, c)
+	}
+	if len(conditions) == 1 {
+		return first, nil
+	}
+	return conj(conditions...), nil
+}
+
+func (p *whereParser) parseNot() (filter.Condition, error) {
+	if p.peekKeyword("NOT") {
+		p.pos++
+		c, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return filter.Not(c), nil
+	}
+	if p.peekSymbol("(") {
+		p.pos++
+		c, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+	return p.parsePredicate()
+}
+
+func (p *whereParser) parsePredicate() (filter.Condition, error) {
+	t := p.peek()
+	if p.done() || t.kind != identToken {
+		if t.kind == numberToken {
+			return nil, fmt.Errorf("constant conditions are not supported at position %d", t.pos)
+		}
+		return nil, p.unexpected("column")
+	}
+	p.pos++
+	field, err := p.options.ColumnMapperFunc(strings.ReplaceAll(t.text, `"`, ""))
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case p.peekKeyword("IS"):
+		p.pos++
+		not := p.peekKeyword("NOT")
+		if not {
+			p.pos++
+		}
+		if err := p.expectKeyword("NULL"); err != nil {
+			return nil, err
+		}
+		if not {
+			return filter.NotNil(field), nil
+		}
+		return filter.IsNil(field), nil
+	case p.peekKeyword("NOT"), p.peekKeyword("IN"):
+		not := p.peekKeyword("NOT")
+		if not {
+			p.pos++
+		}
+		if err := p.expectKeyword("IN"); err != nil {
+			return nil, err
+		}
+		values, err := p.parseList("(", ")")
+		if err != nil {
+			return nil, err
+		}
+		if not {
+			return filter.Not(filter.In(field, values)), nil
+		}
+		return filter.In(field, values), nil
+	case p.peekKeyword("ILIKE"):
+		p.pos++
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		pattern, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("ILIKE pattern must be a string but was of type %T", value)
+		}
+		return likeCondition(field, pattern)
+	case p.peekSymbol("="):
+		p.pos++
+		if p.peekKeyword("ANY") {
+			p.pos++
+			if err := p.expectSymbol("("); err != nil {
+				return nil, err
+			}
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectSymbol(")"); err != nil {
+				return nil, err
+			}
+			// Lists bound as a single array parameter are emitted for In, see WithArrayParameters.
+			if isListValue(value) {
+				return filter.In(field, value), nil
+			}
+			return filter.ArrayContains(field, value), nil
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		return filter.Equals(field, value), nil
+	case p.peekSymbol("@>"), p.peekSymbol("<@"), p.peekSymbol("&&"):
+		op := p.peek().text
+		p.pos++
+		values, err := p.parseArray()
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case "@>":
+			return filter.ArrayContainsArray(field, values), nil
+		case "<@":
+			return filter.ArrayIsContained(field, values), nil
+		}
+		return filter.Overlaps(field, values), nil
+	case p.peekSymbol("~"), p.peekSymbol("!~"):
+		op := p.peek().text
+		p.pos++
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		expression, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("regular expression must be a string but was of type %T", value)
+		}
+		if op == "!~" {
+			return filter.NotRegex(field, expression), nil
+		}
+		return filter.Regex(field, expression), nil
+	}
+
+	comparisons := map[string]func(field string, value any) filter.Condition{
+		"<>": filter.NotEquals,
+		"!=": filter.NotEquals,
+		"<":  filter.LowerThan,
+		"<=": filter.LowerThanOrEqual,
+		">":  filter.GreaterThan,
+		">=": filter.GreaterThanOrEqual,
+	}
+	op := p.peek()
+	compare, ok := comparisons[op.text]
+	if p.done() || op.kind != symbolToken || !ok {
+		return nil, p.unexpected("operator")
+	}
+	p.pos++
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	return compare(field, value), nil
+}
+
+// parseArray parses ARRAY[...] or a placeholder bound to a list.
+func (p *whereParser) parseArray() (any, error) {
+	if p.peekKeyword("ARRAY") {
+		p.pos++
+		return p.parseList("[", "]")
+	}
+	return p.parseValue()
+}
+
+func (p *whereParser) parseList(open string, close string) ([]any, error) {
+	if err := p.expectSymbol(open); err != nil {
+		return nil, err
+	}
+	var values []any
+	for !p.peekSymbol(close) {
+		if len(values) > 0 {
+			if err := p.expectSymbol(","); err != nil {
+				return nil, err
+			}
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	p.pos++
+	return values, nil
+}
+
+func (p *whereParser) parseValue() (any, error) {
+	t := p.peek()
+	if p.done() {
+		return nil, p.unexpected("value")
+	}
+	p.pos++
+	switch t.kind {
+	case stringToken:
+		return t.text, nil
+	case numberToken:
+		i, err := strconv.Atoi(t.text)
+		if err == nil {
+			return i, nil
+		}
+		if errors.Is(err, strconv.ErrRange) {
+			return nil, fmt.Errorf("integer out of range at position %d: %s", t.pos, t.text)
+		}
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number at position %d: %s", t.pos, t.text)
+		}
+		return f, nil
+	case placeholderToken:
+		return p.arg(t)
+	case identToken:
+		switch strings.ToUpper(t.text) {
+		case "TRUE":
+			return true, nil
+		case "FALSE":
+			return false, nil
+		case "NULL":
+			return nil, nil
+		}
+	}
+	p.pos--
+	return nil, p.unexpected("value")
+}
+
+func (p *whereParser) arg(t sqlToken) (any, error) {
+	index := p.nextArg
+	if t.text != "?" {
+		n, err := strconv.Atoi(strings.TrimLeft(t.text, "$@pP"))
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid placeholder at position %d: %s", t.pos, t.text)
+		}
+		index = n - 1
+	}
+	if index >= len(p.args) {
+		return nil, fmt.Errorf("missing argument for placeholder %s at position %d", t.text, t.pos)
+	}
+	p.nextArg = index + 1
+	return p.args[index], nil
+}
+
+// likeCondition translates an ILIKE pattern emitted for Contains, StartsWith or EndsWith.
+func likeCondition(field string, pattern string) (filter.Condition, error) {
+	var b strings.Builder
+	prefix, suffix, escaped := false, false, false
+	runes := []rune(pattern)
+	for i, r := range runes {
+		switch {
+		case escaped:
+			escaped = false
+			b.WriteRune(r)
+		case r == likeEscapeChar:
+			escaped = true
+		case r == '%' && i == 0:
+			prefix = true
+		case r == '%' && i == len(runes)-1:
+			suffix = true
+		case r == '%' || r == '_':
+			return nil, fmt.Errorf("unsupported wildcard in ILIKE pattern: %s", pattern)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	switch {
+	case prefix && suffix:
+		return filter.Contains(field, b.String()), nil
+	case suffix:
+		return StartsWith(field, b.String()), nil
+	case prefix:
+		return EndsWith(field, b.String()), nil
+	}
+	return nil, fmt.Errorf("unsupported ILIKE pattern: %s", pattern)
+}
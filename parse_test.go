@@ -0,0 +1,170 @@
+package filtersquirrel
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"github.com/xafelium/filter"
+	"strings"
+	"testing"
+)
+
+// TestParseWhereRoundTrip parses the SQL generated in TestApplyFilter and checks that the parsed condition
+// generates the same SQL again.
+func TestParseWhereRoundTrip(t *testing.T) {
+	stripAlias := WithColumnMapperFunc(func(column string) (string, error) {
+		_, field, found := strings.Cut(column, ".")
+		if !found {
+			return column, nil
+		}
+		return field, nil
+	})
+
+	for _, test := range applyFilterTests() {
+		_, where, found := strings.Cut(test.expectedSql, " WHERE ")
+		if test.errContains != "" || !found || where == sqlFalse {
+			continue
+		}
+		t.Run(test.name, func(t *testing.T) {
+			condition, err := ParseWhere(where, test.expectedArgs, stripAlias)
+			require.NoError(t, err)
+
+			var opts []Option
+			if test.mapperFunc != nil {
+				opts = append(opts, WithMapperFunc(test.mapperFunc))
+			}
+			builder, _, err := ApplyFilter(test.builder, condition, opts...)
+			require.NoError(t, err)
+			sql, args, err := builder.ToSql()
+			require.NoError(t, err)
+			require.Equal(t, test.expectedSql, sql)
+			require.Equal(t, test.expectedArgs, args)
+		})
+	}
+}
+
+func TestParseWhere(t *testing.T) {
+	tests := []struct {
+		name        string
+		sql         string
+		args        []any
+		expected    filter.Condition
+		errContains string
+		opts        []Option
+	}{
+		{
+			name:     "empty",
+			sql:      "",
+			expected: filter.Where(nil),
+		},
+		{
+			name:     "literals",
+			sql:      "WHERE name = 'O''Brien' AND age >= 18 AND score < -1.5 AND active = TRUE",
+			expected: filter.Where(filter.And(filter.Equals("name", "O'Brien"), filter.GreaterThanOrEqual("age", 18), filter.LowerThan("score", -1.5), filter.Equals("active", true))),
+		},
+		{
+			name:     "question mark placeholders",
+			sql:      "a <> ? OR b <= ? OR c != ?",
+			args:     []any{1, 2, 3},
+			expected: filter.Where(filter.Or(filter.NotEquals("a", 1), filter.LowerThanOrEqual("b", 2), filter.NotEquals("c", 3))),
+		},
+		{
+			name:     "sql server placeholders",
+			sql:      "a = @p2 AND b = @p1",
+			args:     []any{1, 2},
+			expected: filter.Where(filter.And(filter.Equals("a", 2), filter.Equals("b", 1))),
+		},
+		{
+			name:     "precedence",
+			sql:      "a = 1 OR b = 2 AND NOT c IS NULL",
+			expected: filter.Where(filter.Or(filter.Equals("a", 1), filter.And(filter.Equals("b", 2), filter.Not(filter.IsNil("c"))))),
+		},
+		{
+			name:     "not in",
+			sql:      `"u"."id" NOT IN (1, 2)`,
+			expected: filter.Where(filter.Not(filter.In("u.id", []any{1, 2}))),
+		},
+		{
+			name:     "like patterns",
+			sql:      `a ILIKE 'x\%%' AND b ILIKE '%\_y'`,
+			expected: filter.Where(filter.And(StartsWith("a", "x%"), EndsWith("b", "_y"))),
+		},
+		{
+			name:     "arrays",
+			sql:      "ids = ANY ($1) AND tags @> $2",
+			args:     []any{4, []string{"a"}},
+			expected: filter.Where(filter.And(filter.ArrayContains("ids", 4), filter.ArrayContainsArray("tags", []string{"a"}))),
+		},
+		{
+			name:     "array parameter",
+			sql:      "id = ANY ($1)",
+			args:     []any{[]int{1, 2}},
+			expected: filter.Where(filter.In("id", []int{1, 2})),
+		},
+		{
+			name: "column mapper",
+			sql:  "u.email_address = 'x'",
+			opts: []Option{WithColumnMapperFunc(func(column string) (string, error) {
+				if column == "u.email_address" {
+					return "email", nil
+				}
+				return "", fmt.Errorf("unknown column: %s", column)
+			})},
+			expected: filter.Where(filter.Equals("email", "x")),
+		},
+		{
+			name:        "unknown column",
+			sql:         "password = 'x'",
+			opts:        []Option{WithColumnMapperFunc(func(column string) (string, error) { return "", fmt.Errorf("unknown column: %s", column) })},
+			errContains: "unknown column: password",
+		},
+		{
+			name:        "wildcard in the middle",
+			sql:         "a ILIKE 'x%y'",
+			errContains: "unsupported wildcard in ILIKE pattern: x%y",
+		},
+		{
+			name:        "constant condition",
+			sql:         "(1=0)",
+			errContains: "constant conditions are not supported at position 1",
+		},
+		{
+			name:        "integer out of range",
+			sql:         "a = 100000000000000000000",
+			errContains: "integer out of range at position 4: 100000000000000000000",
+		},
+		{
+			name:        "missing argument",
+			sql:         "a = $2",
+			args:        []any{1},
+			errContains: "missing argument for placeholder $2 at position 4",
+		},
+		{
+			name:        "missing parenthesis",
+			sql:         "(a = 1",
+			errContains: "expected ) at position -1 but got end of input",
+		},
+		{
+			name:        "trailing tokens",
+			sql:         "a = 1 b",
+			errContains: "unexpected token at position 6: b",
+		},
+		{
+			name:        "unterminated string",
+			sql:         "a = 'x",
+			errContains: "unterminated string at position 4",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			condition, err := ParseWhere(test.sql, test.args, test.opts...)
+
+			if test.errContains != "" {
+				require.ErrorContains(t, err, test.errContains)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, condition)
+		})
+	}
+}
@@ -0,0 +1,323 @@
+package filtersquirrel
+
+import (
+	"errors"
+	"fmt"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/xafelium/filter"
+	"reflect"
+)
+
+// Range is a range of values. OverlapsCondition matches range columns and periods overlapping a Range value.
+// A nil bound is unbounded.
+type Range struct {
+	Lower          any
+	Upper          any
+	LowerInclusive bool
+	UpperInclusive bool
+}
+
+// NewRange creates a range including the lower and excluding the upper bound, i.e. [lower, upper).
+func NewRange(lower any, upper any) Range {
+	return Range{Lower: lower, Upper: upper, LowerInclusive: true}
+}
+
+// Bounds returns the inclusivity of the bounds in the notation of PostgreSQL, e.g. "[)".
+func (r Range) Bounds() string {
+	bounds := []byte("()")
+	if r.LowerInclusive {
+		bounds[0] = '['
+	}
+	if r.UpperInclusive {
+		bounds[1] = ']'
+	}
+	return string(bounds)
+}
+
+// String returns the string representation of the range, e.g. [1, 5).
+func (r Range) String() string {
+	bound := func(v any) string {
+		if v == nil {
+			return ""
+		}
+		return fmt.Sprint(v)
+	}
+	bounds := r.Bounds()
+	return fmt.Sprintf("%c%s, %s%c", bounds[0], bound(r.Lower), bound(r.Upper), bounds[1])
+}
+
+// Period is a range stored in the columns of two domain fields. Its start is included and its end is excluded.
+type Period struct {
+	Start string
+	End   string
+}
+
+// rangeValue returns the value as Range if it is one.
+func rangeValue(value any) (Range, bool) {
+	switch v := value.(type) {
+	case Range:
+		return v, true
+	case *Range:
+		if v != nil {
+			return *v, true
+		}
+	}
+	return Range{}, false
+}
+
+// coerce coerces both bounds with f.
+func (r Range) coerce(f func(value any) (any, error)) (Range, error) {
+	lower, err := f(r.Lower)
+	if err != nil {
+		return Range{}, err
+	}
+	upper, err := f(r.Upper)
+	if err != nil {
+		return Range{}, err
+	}
+	r.Lower, r.Upper = lower, upper
+	return r, nil
+}
+
+// rangeTypes are the PostgreSQL range types of the types of their bounds.
+var rangeTypes = map[string]string{
+	"timestamptz":      "tstzrange",
+	"timestamp":        "tsrange",
+	"date":             "daterange",
+	"smallint":         "int4range",
+	"integer":          "int4range",
+	"bigint":           "int8range",
+	"numeric":          "numrange",
+	"real":             "numrange",
+	"double precision": "numrange",
+}
+
+// rangeSubtypes are the types of the bounds of the built-in PostgreSQL range types.
+var rangeSubtypes = map[string]string{
+	"tstzrange": "timestamptz",
+	"tsrange":   "timestamp",
+	"daterange": "date",
+	"int4range": "integer",
+	"int8range": "bigint",
+	"numrange":  "numeric",
+}
+
+// rangeType returns the range type of the domain field, which is configured with WithRangeType or derived from the
+// type of the values.
+func (ctx *ApplyContext) rangeType(field string, values ...any) (string, error) {
+	if rangeType, ok := ctx.options.RangeTypes[field]; ok {
+		if !sqlTypePattern.MatchString(rangeType) {
+			return "", fmt.Errorf("invalid range type %q for field %s", rangeType, field)
+		}
+		return rangeType, nil
+	}
+	for _, v := range values {
+		t := reflect.TypeOf(v)
+		for t != nil && t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t == nil {
+			continue
+		}
+		if rangeType, ok := rangeTypes[ctx.sqlTypeOf(field, t)]; ok {
+			return rangeType, nil
+		}
+	}
+	return "", fmt.Errorf("unknown range type of field %s", field)
+}
+
+// mapPeriod maps the start and end of the period to their columns.
+func (ctx *ApplyContext) mapPeriod(period Period) (string, string, error) {
+	start, err := ctx.MapField(period.Start)
+	if err != nil {
+		return "", "", err
+	}
+	end, err := ctx.MapField(period.End)
+	if err != nil {
+		return "", "", err
+	}
+	return start, end, nil
+}
+
+// rangeOverlaps matches the range column or the period of the domain field if it overlaps the range.
+// Periods are compared with their columns, e.g. start < $1 AND end > $2, on all dialects.
+func (ctx *ApplyContext) rangeOverlaps(field string, r Range) (sq.Sqlizer, error) {
+	value, err := ctx.coerceValue(field, r)
+	if err != nil {
+		return nil, err
+	}
+	r = value.(Range)
+	if period, ok := ctx.options.Periods[field]; ok {
+		return ctx.applyPeriod(period, r)
+	}
+	if !ctx.Dialect().SupportsRangeTypes() {
+		return nil, unsupported(ctx.Dialect().Name(), "range types")
+	}
+	fieldName, err := ctx.MapField(field)
+	if err != nil {
+		return nil, err
+	}
+	if r.Lower == nil && r.Upper == nil {
+		// An unbounded range overlaps every range except the empty one, regardless of the range type.
+		return sq.Expr(fmt.Sprintf("NOT isempty(%s)", fieldName)), nil
+	}
+	rangeType, err := ctx.rangeType(field, r.Lower, r.Upper)
+	if err != nil {
+		return nil, err
+	}
+	return sq.Expr(fmt.Sprintf("%s && %s(?,?,'%s')", fieldName, rangeType, r.Bounds()), r.Lower, r.Upper), nil
+}
+
+func applyRangeContains(condition filter.Condition, ctx *ApplyContext) (any, error) {
+	if condition == nil {
+		return nil, fmt.Errorf("condition is nil")
+	}
+	c, ok := condition.(*RangeContainsCondition)
+	if !ok {
+		return nil, fmt.Errorf("condition is no RangeContainsCondition")
+	}
+	value, err := ctx.coerceValue(c.Field, c.Value)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, fmt.Errorf("value cannot be nil")
+	}
+	if isListType(value) {
+		return nil, fmt.Errorf("value cannot be an array or slice")
+	}
+	if period, ok := ctx.options.Periods[c.Field]; ok {
+		return ctx.applyPeriod(period, value)
+	}
+	if !ctx.Dialect().SupportsRangeTypes() {
+		return nil, unsupported(ctx.Dialect().Name(), "range types")
+	}
+	fieldName, err := ctx.MapField(c.Field)
+	if err != nil {
+		return nil, err
+	}
+	rangeType, err := ctx.rangeType(c.Field, value)
+	if err != nil {
+		return nil, err
+	}
+	if subtype, ok := rangeSubtypes[rangeType]; ok {
+		return sq.Expr(fmt.Sprintf("%s @> ?::%s", fieldName, subtype), value), nil
+	}
+	return sq.Expr(fmt.Sprintf("%s @> ?", fieldName), value), nil
+}
+
+// applyPeriod translates the comparisons of the period columns returned by periodConditions, which the evaluator
+// uses as well. They are part of the translated condition, so they neither count towards the limits nor report
+// errors with paths of their own.
+func (ctx *ApplyContext) applyPeriod(period Period, value any) (sq.Sqlizer, error) {
+	inlineLeaves := ctx.inlineLeaves
+	ctx.inlineLeaves = true
+	defer func() { ctx.inlineLeaves = inlineLeaves }()
+	sqlObj, err := ctx.Apply(periodConditions(period, value))
+	if err != nil {
+		var conditionErr *ConditionError
+		if errors.As(err, &conditionErr) {
+			return nil, conditionErr.Err
+		}
+		return nil, err
+	}
+	switch v := sqlObj.(type) {
+	case nil:
+		return sq.Expr(sqlTrue), nil
+	case sq.Sqlizer:
+		return v, nil
+	case []sq.Sqlizer:
+		return sq.And(v), nil
+	}
+	return nil, fmt.Errorf("unexpected data type: %T", sqlObj)
+}
+
+// periodConditions returns the comparisons of the columns of the period rendered for a RangeContainsCondition or
+// an OverlapsCondition with a Range value.
+func periodConditions(period Period, value any) filter.Condition {
+	r, ok := rangeValue(value)
+	if !ok {
+		return filter.And(filter.LowerThanOrEqual(period.Start, value), filter.GreaterThan(period.End, value))
+	}
+	var conditions []filter.Condition
+	switch {
+	case r.Upper != nil && r.UpperInclusive:
+		conditions = append(conditions, filter.LowerThanOrEqual(period.Start, r.Upper))
+	case r.Upper != nil:
+		conditions = append(conditions, filter.LowerThan(period.Start, r.Upper))
+	}
+	if r.Lower != nil {
+		conditions = append(conditions, filter.GreaterThan(period.End, r.Lower))
+	}
+	switch len(conditions) {
+	case 0:
+		return filter.Where(nil)
+	case 1:
+		return conditions[0]
+	}
+	return filter.And(conditions...)
+}
+
+// canonical converts the bounds of integer ranges to [), like PostgreSQL does for discrete range types.
+func (r Range) canonical() Range {
+	r.Lower, r.Upper = normalizeValue(r.Lower), normalizeValue(r.Upper)
+	if lower, ok := r.Lower.(int64); ok && !r.LowerInclusive {
+		r.Lower, r.LowerInclusive = lower+1, true
+	}
+	if upper, ok := r.Upper.(int64); ok && r.UpperInclusive {
+		r.Upper, r.UpperInclusive = upper+1, false
+	}
+	return r
+}
+
+// isEmpty reports whether the canonical range contains no values.
+func (r Range) isEmpty() (bool, error) {
+	if r.Lower == nil || r.Upper == nil {
+		return false, nil
+	}
+	cmp, err := compareValues(r.Lower, r.Upper)
+	return cmp > 0 || (cmp == 0 && !(r.LowerInclusive && r.UpperInclusive)), err
+}
+
+// endsBefore reports whether the canonical range ends before the other one starts.
+func (r Range) endsBefore(other Range) (bool, error) {
+	if r.Upper == nil || other.Lower == nil {
+		return false, nil
+	}
+	cmp, err := compareValues(r.Upper, other.Lower)
+	return cmp < 0 || (cmp == 0 && !(r.UpperInclusive && other.LowerInclusive)), err
+}
+
+// overlaps implements "r && other".
+func (r Range) overlaps(other Range) (bool, error) {
+	r, other = r.canonical(), other.canonical()
+	for _, x := range []Range{r, other} {
+		if empty, err := x.isEmpty(); empty || err != nil {
+			return false, err
+		}
+	}
+	before, err := r.endsBefore(other)
+	if before || err != nil {
+		return false, err
+	}
+	after, err := other.endsBefore(r)
+	return !after, err
+}
+
+// contains implements "r @> v".
+func (r Range) contains(v any) (bool, error) {
+	r = r.canonical()
+	if r.Lower != nil {
+		cmp, err := compareValues(r.Lower, v)
+		if err != nil || cmp > 0 || (cmp == 0 && !r.LowerInclusive) {
+			return false, err
+		}
+	}
+	if r.Upper != nil {
+		cmp, err := compareValues(v, r.Upper)
+		if err != nil || cmp > 0 || (cmp == 0 && !r.UpperInclusive) {
+			return false, err
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,199 @@
+package filtersquirrel
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/require"
+	"github.com/xafelium/filter"
+	"testing"
+	"time"
+)
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestApplyFilterWithRanges(t *testing.T) {
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 5, 8, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		dialect      Dialect
+		opts         []Option
+		filter       filter.Condition
+		expectedSql  string
+		expectedArgs []any
+		errContains  string
+	}{
+		{
+			name:         "overlaps range",
+			dialect:      Postgres,
+			filter:       filter.Where(filter.Overlaps("booked", NewRange(from, to))),
+			expectedSql:  "SELECT * FROM bookings WHERE booked && tstzrange($1,$2,'[)')",
+			expectedArgs: []any{from, to},
+		},
+		{
+			name:         "overlaps unbounded range",
+			dialect:      Postgres,
+			filter:       filter.Where(filter.Overlaps("seats", &Range{Upper: 10, UpperInclusive: true})),
+			expectedSql:  "SELECT * FROM bookings WHERE seats && int8range($1,$2,'(]')",
+			expectedArgs: []any{nil, 10},
+		},
+		{
+			name:        "overlaps range without bounds",
+			dialect:     Postgres,
+			filter:      filter.Where(filter.Overlaps("booked", Range{})),
+			expectedSql: "SELECT * FROM bookings WHERE NOT isempty(booked)",
+		},
+		{
+			name:         "overlaps range of configured type",
+			dialect:      Postgres,
+			opts:         []Option{WithRangeType("daterange", "booked"), WithFieldType(TimeType, "booked")},
+			filter:       filter.Where(filter.Overlaps("booked", NewRange("2024-05-01", "2024-05-08"))),
+			expectedSql:  "SELECT * FROM bookings WHERE booked && daterange($1,$2,'[)')",
+			expectedArgs: []any{from, to},
+		},
+		{
+			name:         "range contains",
+			dialect:      Postgres,
+			filter:       filter.Where(RangeContains("booked", from)),
+			expectedSql:  "SELECT * FROM bookings WHERE booked @> $1::timestamptz",
+			expectedArgs: []any{from},
+		},
+		{
+			name:         "range contains with configured type",
+			dialect:      Postgres,
+			opts:         []Option{WithRangeType("numrange", "price")},
+			filter:       filter.Where(RangeContains("price", 10)),
+			expectedSql:  "SELECT * FROM bookings WHERE price @> $1::numeric",
+			expectedArgs: []any{10},
+		},
+		{
+			name:        "unknown range type",
+			dialect:     Postgres,
+			filter:      filter.Where(RangeContains("booked", "2024-05-01")),
+			errContains: "unknown range type of field booked",
+		},
+		{
+			name:        "invalid range type",
+			dialect:     Postgres,
+			opts:        []Option{WithRangeType("daterange)--", "booked")},
+			filter:      filter.Where(RangeContains("booked", from)),
+			errContains: `invalid range type "daterange)--" for field booked`,
+		},
+		{
+			name:        "range types not supported",
+			dialect:     MySQL,
+			filter:      filter.Where(filter.Overlaps("booked", NewRange(from, to))),
+			errContains: "range types cannot be expressed in mysql",
+		},
+		{
+			name:         "overlaps period",
+			dialect:      MySQL,
+			opts:         []Option{WithPeriod("booked", "start", "end")},
+			filter:       filter.Where(filter.Overlaps("booked", NewRange(from, to))),
+			expectedSql:  "SELECT * FROM bookings WHERE (start < ? AND end > ?)",
+			expectedArgs: []any{to, from},
+		},
+		{
+			name:         "overlaps period with inclusive upper bound",
+			dialect:      Postgres,
+			opts:         []Option{WithPeriod("booked", "start", "end")},
+			filter:       filter.Where(filter.Overlaps("booked", Range{Upper: to, UpperInclusive: true})),
+			expectedSql:  "SELECT * FROM bookings WHERE start <= $1",
+			expectedArgs: []any{to},
+		},
+		{
+			name:        "overlaps unbounded period",
+			dialect:     SQLite,
+			opts:        []Option{WithPeriod("booked", "start", "end")},
+			filter:      filter.Where(filter.Overlaps("booked", Range{})),
+			expectedSql: "SELECT * FROM bookings WHERE (1=1)",
+		},
+		{
+			name:    "period of to-many join",
+			dialect: SQLite,
+			opts: []Option{
+				WithPeriod("contract", "c.start", "c.end"),
+				WithJoin(Join{Alias: "c", Table: "contracts c", On: "c.booking_id = id", ToMany: true}),
+				WithMaxConditions(1),
+			},
+			filter:       filter.Where(filter.Overlaps("contract", NewRange(1, 5))),
+			expectedSql:  "SELECT * FROM bookings WHERE EXISTS (SELECT 1 FROM contracts c WHERE c.booking_id = id AND (c.start < ? AND c.end > ?))",
+			expectedArgs: []any{5, 1},
+		},
+		{
+			name:         "period contains",
+			dialect:      SQLServer,
+			opts:         []Option{WithPeriod("booked", "start", "end"), WithFieldType(TimeType, "booked")},
+			filter:       filter.Where(RangeContains("booked", "2024-05-01")),
+			expectedSql:  "SELECT * FROM bookings WHERE (start <= ? AND end > ?)",
+			expectedArgs: []any{from, from},
+		},
+		{
+			name:        "range contains nil",
+			dialect:     Postgres,
+			filter:      filter.Where(RangeContains("booked", nil)),
+			errContains: "value cannot be nil",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			builder := sq.Select("*").From("bookings")
+			if test.dialect == Postgres {
+				builder = builder.PlaceholderFormat(sq.Dollar)
+			}
+			opts := append([]Option{WithDialect(test.dialect)}, test.opts...)
+			builder, _, err := ApplyFilter(builder, test.filter, opts...)
+
+			if test.errContains != "" {
+				require.ErrorContains(t, err, test.errContains)
+				return
+			}
+			require.NoError(t, err)
+			sql, args, err := builder.ToSql()
+			require.NoError(t, err)
+			require.Equal(t, test.expectedSql, sql)
+			require.Equal(t, test.expectedArgs, args)
+		})
+	}
+}
+
+func TestEvaluateRanges(t *testing.T) {
+	row := MapAccessor(map[string]any{
+		"start":  10,
+		"end":    20,
+		"seats":  Range{Lower: 10, Upper: 20, LowerInclusive: false, UpperInclusive: true},
+		"closed": nil,
+	})
+	opts := []Option{WithPeriod("period", "start", "end"), WithPeriod("open", "start", "closed")}
+	tests := []struct {
+		filter  filter.Condition
+		matches bool
+	}{
+		{filter: filter.Overlaps("period", NewRange(0, 10)), matches: false},
+		{filter: filter.Overlaps("period", Range{Lower: 0, Upper: 10, UpperInclusive: true}), matches: true},
+		{filter: filter.Overlaps("period", NewRange(19, nil)), matches: true},
+		{filter: filter.Overlaps("period", NewRange(20, nil)), matches: false},
+		{filter: RangeContains("period", 10), matches: true},
+		{filter: RangeContains("period", 20), matches: false},
+		{filter: filter.Overlaps("open", NewRange(0, 30)), matches: false},
+		{filter: filter.Not(filter.Overlaps("open", NewRange(0, 30))), matches: false},
+		{filter: filter.Overlaps("seats", NewRange(0, 11)), matches: false},
+		{filter: filter.Overlaps("seats", Range{Lower: 0, Upper: 11, UpperInclusive: true}), matches: true},
+		{filter: filter.Overlaps("seats", NewRange(5, 5)), matches: false},
+		{filter: filter.Overlaps("seats", Range{}), matches: true},
+		{filter: RangeContains("seats", 10), matches: false},
+		{filter: RangeContains("seats", 20), matches: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.filter.String(), func(t *testing.T) {
+			matches, err := Evaluate(test.filter, row, opts...)
+			require.NoError(t, err)
+			require.Equal(t, test.matches, matches)
+		})
+	}
+}
+
+func TestRangeString(t *testing.T) {
+	require.Equal(t, "[1, 5)", NewRange(1, 5).String())
+	require.Equal(t, "(, 5]", Range{Upper: 5, UpperInclusive: true}.String())
+}
@@ -0,0 +1,41 @@
+package filtersquirrel
+
+import (
+	"github.com/xafelium/filter"
+	"sync"
+)
+
+// ConditionBuilderFunc translates a condition into SQL. The result must be a sq.Sqlizer, a []sq.Sqlizer or nil.
+type ConditionBuilderFunc func(c filter.Condition, ctx *ApplyContext) (any, error)
+
+var (
+	conditionBuilders     = make(map[string]ConditionBuilderFunc)
+	conditionBuildersLock sync.RWMutex
+)
+
+// RegisterConditionBuilder registers the builder for the condition type globally.
+// An already registered builder, including the built-in ones, is replaced.
+func RegisterConditionBuilder(conditionType string, b ConditionBuilderFunc) {
+	conditionBuildersLock.Lock()
+	defer conditionBuildersLock.Unlock()
+	if b == nil {
+		delete(conditionBuilders, conditionType)
+		return
+	}
+	conditionBuilders[conditionType] = b
+}
+
+func lookupConditionBuilder(conditionType string) (ConditionBuilderFunc, bool) {
+	conditionBuildersLock.RLock()
+	defer conditionBuildersLock.RUnlock()
+	b, ok := conditionBuilders[conditionType]
+	return b, ok
+}
+
+// conditionBuilder prefers the builders passed with WithConditionBuilder over the global ones.
+func (ctx *ApplyContext) conditionBuilder(conditionType string) (ConditionBuilderFunc, bool) {
+	if b, ok := ctx.options.ConditionBuilders[conditionType]; ok {
+		return b, true
+	}
+	return lookupConditionBuilder(conditionType)
+}
@@ -0,0 +1,95 @@
+package filtersquirrel
+
+import (
+	"fmt"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/require"
+	"github.com/xafelium/filter"
+	"testing"
+)
+
+const withinRadiusConditionType = "WithinRadiusCondition"
+
+type withinRadiusCondition struct {
+	Field  string
+	Lat    float64
+	Lon    float64
+	Radius float64
+}
+
+func (c *withinRadiusCondition) String() string {
+	return fmt.Sprintf("%s within %v of (%v, %v)", c.Field, c.Radius, c.Lat, c.Lon)
+}
+
+func (c *withinRadiusCondition) Type() string {
+	return withinRadiusConditionType
+}
+
+func applyWithinRadius(condition filter.Condition, ctx *ApplyContext) (any, error) {
+	c, ok := condition.(*withinRadiusCondition)
+	if !ok {
+		return nil, fmt.Errorf("condition is no withinRadiusCondition")
+	}
+	fieldName, err := ctx.MapField(c.Field)
+	if err != nil {
+		return nil, err
+	}
+	return sq.Expr(fmt.Sprintf("ST_DWithin(%s, ST_MakePoint(?, ?), ?)", fieldName), c.Lon, c.Lat, c.Radius), nil
+}
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestRegisterConditionBuilder(t *testing.T) {
+	condition := filter.Where(filter.And(
+		filter.Equals("name", "cafe"),
+		&withinRadiusCondition{Field: "location", Lat: 1.5, Lon: 2.5, Radius: 100},
+	))
+	mapperFunc := WithMapperFunc(func(fieldName string) (string, error) {
+		return fmt.Sprintf("p.%s", fieldName), nil
+	})
+
+	_, _, err := ApplyFilter(sq.Select("*").From("places p"), condition, mapperFunc)
+	require.ErrorContains(t, err, "unknown condition: WithinRadiusCondition")
+
+	RegisterConditionBuilder(withinRadiusConditionType, applyWithinRadius)
+	defer RegisterConditionBuilder(withinRadiusConditionType, nil)
+
+	builder, tableAliases, err := ApplyFilter(sq.Select("*").From("places p"), condition, mapperFunc)
+	require.NoError(t, err)
+	sql, args, err := builder.ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT * FROM places p WHERE (p.name = ? AND ST_DWithin(p.location, ST_MakePoint(?, ?), ?))", sql)
+	require.Equal(t, []any{"cafe", 2.5, 1.5, 100.0}, args)
+	require.Equal(t, []string{"p"}, tableAliases)
+}
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestWithConditionBuilder(t *testing.T) {
+	condition := filter.Where(filter.Not(filter.Contains("name", "abc")))
+
+	// Overrides the built-in builder and delegates the nested condition back to the context.
+	negate := func(c filter.Condition, ctx *ApplyContext) (any, error) {
+		inner, err := ctx.Apply(c.(*filter.NotCondition).Condition)
+		if err != nil {
+			return nil, err
+		}
+		sql, args, err := inner.(sq.Sqlizer).ToSql()
+		if err != nil {
+			return nil, err
+		}
+		return sq.Expr(fmt.Sprintf("(%s) IS NOT TRUE", sql), args...), nil
+	}
+
+	builder, _, err := ApplyFilter(sq.Select("*").From("x"), condition, WithConditionBuilder(filter.NotConditionType, negate))
+	require.NoError(t, err)
+	sql, args, err := builder.ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT * FROM x WHERE (name ILIKE ?) IS NOT TRUE", sql)
+	require.Equal(t, []any{"%abc%"}, args)
+
+	// The global registry is left untouched.
+	builder, _, err = ApplyFilter(sq.Select("*").From("x"), condition)
+	require.NoError(t, err)
+	sql, _, err = builder.ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT * FROM x WHERE NOT (name ILIKE ?)", sql)
+}
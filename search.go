@@ -0,0 +1,52 @@
+package filtersquirrel
+
+import (
+	"fmt"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/xafelium/filter"
+	"regexp"
+	"strings"
+)
+
+// searchLanguage restricts the search languages, as they are rendered into the SQL to match the expression indexes.
+var searchLanguage = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+func applySearch(condition filter.Condition, ctx *ApplyContext) (any, error) {
+	if condition == nil {
+		return nil, fmt.Errorf("condition is nil")
+	}
+	c, ok := condition.(*SearchCondition)
+	if !ok {
+		return nil, fmt.Errorf("condition is no SearchCondition")
+	}
+	fieldName, err := ctx.MapField(c.Field)
+	if err != nil {
+		return nil, err
+	}
+	language, ok := ctx.options.FullTextSearch[c.Field]
+	if !ok {
+		language = ctx.options.SearchLanguage
+	}
+	return ctx.search(fieldName, c.Query, language)
+}
+
+// search renders the full-text search of the dialect. Empty queries match all rows.
+func (ctx *ApplyContext) search(fieldName string, query string, language string) (sq.Sqlizer, error) {
+	if language != "" && !searchLanguage.MatchString(language) {
+		return nil, fmt.Errorf("invalid search language: %s", language)
+	}
+	if strings.TrimSpace(query) == "" {
+		return sq.Expr(sqlTrue), nil
+	}
+	return ctx.Dialect().Search(fieldName, query, language)
+}
+
+// fts5Query quotes the words of the query as FTS5 strings, so they match all words instead of being parsed as
+// FTS5 query syntax.
+func fts5Query(query string) string {
+	words := strings.Fields(query)
+	for i, word := range words {
+		words[i] = `"` + strings.ReplaceAll(word, `"`, `""`) + `"`
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,102 @@
+package filtersquirrel
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/require"
+	"github.com/xafelium/filter"
+	"testing"
+)
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestApplyFilterWithSearch(t *testing.T) {
+	tests := []struct {
+		name         string
+		dialect      Dialect
+		opts         []Option
+		filter       filter.Condition
+		expectedSql  string
+		expectedArgs []any
+		errContains  string
+	}{
+		{
+			name:         "postgres search",
+			dialect:      Postgres,
+			filter:       filter.Where(Search("title", `"fat cat" -dog`)),
+			expectedSql:  "SELECT * FROM posts WHERE to_tsvector(title) @@ websearch_to_tsquery($1)",
+			expectedArgs: []any{`"fat cat" -dog`},
+		},
+		{
+			name:         "postgres search with language",
+			dialect:      Postgres,
+			opts:         []Option{WithSearchLanguage("english")},
+			filter:       filter.Where(Search("title", "cats")),
+			expectedSql:  "SELECT * FROM posts WHERE to_tsvector('english', title) @@ websearch_to_tsquery('english', $1)",
+			expectedArgs: []any{"cats"},
+		},
+		{
+			name:         "postgres contains with full-text search",
+			dialect:      Postgres,
+			opts:         []Option{WithFullTextSearch("german", "body"), WithSearchLanguage("english")},
+			filter:       filter.Where(filter.And(filter.Contains("body", "katzen"), filter.Contains("title", "cat"))),
+			expectedSql:  "SELECT * FROM posts WHERE (to_tsvector('german', body) @@ websearch_to_tsquery('german', $1) AND title ILIKE $2)",
+			expectedArgs: []any{"katzen", "%cat%"},
+		},
+		{
+			name:        "empty query",
+			dialect:     Postgres,
+			filter:      filter.Where(Search("title", " ")),
+			expectedSql: "SELECT * FROM posts WHERE (1=1)",
+		},
+		{
+			name:        "invalid language",
+			dialect:     Postgres,
+			opts:        []Option{WithSearchLanguage("english'")},
+			filter:      filter.Where(Search("title", "cats")),
+			errContains: "invalid search language: english'",
+		},
+		{
+			name:         "mysql search",
+			dialect:      MySQL,
+			opts:         []Option{WithFullTextSearch("english", "title")},
+			filter:       filter.Where(filter.Contains("title", "cats")),
+			expectedSql:  "SELECT * FROM posts WHERE MATCH (title) AGAINST (? IN NATURAL LANGUAGE MODE)",
+			expectedArgs: []any{"cats"},
+		},
+		{
+			name:         "sqlite search",
+			dialect:      SQLite,
+			filter:       filter.Where(Search("title", `fat "cat" OR`)),
+			expectedSql:  "SELECT * FROM posts WHERE title MATCH ?",
+			expectedArgs: []any{`"fat" """cat""" "OR"`},
+		},
+		{
+			name:         "sqlserver search",
+			dialect:      SQLServer,
+			opts:         []Option{WithSearchLanguage("English")},
+			filter:       filter.Where(Search("title", "cats")),
+			expectedSql:  "SELECT * FROM posts WHERE FREETEXT(title, ?, LANGUAGE 'English')",
+			expectedArgs: []any{"cats"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			builder := sq.Select("*").From("posts")
+			if test.dialect == Postgres {
+				builder = builder.PlaceholderFormat(sq.Dollar)
+			}
+			opts := append([]Option{WithDialect(test.dialect)}, test.opts...)
+			builder, _, err := ApplyFilter(builder, test.filter, opts...)
+
+			if test.errContains != "" {
+				require.ErrorContains(t, err, test.errContains)
+				return
+			}
+			require.NoError(t, err)
+			sql, args, err := builder.ToSql()
+			require.NoError(t, err)
+			require.Equal(t, test.expectedSql, sql)
+			require.Equal(t, test.expectedArgs, args)
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package filtersquirrel
+
+import (
+	"fmt"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/xafelium/filter"
+	"strings"
+)
+
+// SortDirection is the direction of a SortField.
+type SortDirection string
+
+const (
+	Asc  SortDirection = "ASC"
+	Desc SortDirection = "DESC"
+)
+
+// NullsOrder places NULL values before or after the other values. The default depends on the database.
+type NullsOrder string
+
+const (
+	NullsDefault NullsOrder = ""
+	NullsFirst   NullsOrder = "NULLS FIRST"
+	NullsLast    NullsOrder = "NULLS LAST"
+)
+
+// SortField sorts by a domain field.
+type SortField struct {
+	Field     string
+	Direction SortDirection
+	Nulls     NullsOrder
+}
+
+// ParseSort parses a comma separated list of field names. Fields prefixed with "-" are sorted descending,
+// e.g. "-created_at,name".
+func ParseSort(s string) ([]SortField, error) {
+	var sort []SortField
+	for _, token := range strings.Split(s, ",") {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+		direction := Asc
+		if strings.HasPrefix(token, "-") {
+			direction = Desc
+		}
+		field := strings.TrimLeft(token, "+-")
+		if field == "" {
+			return nil, fmt.Errorf("invalid sort field: %s", token)
+		}
+		sort = append(sort, SortField{Field: field, Direction: direction})
+	}
+	return sort, nil
+}
+
+// ApplySort adds ORDER BY clauses for the sort fields, which are mapped like the fields of a filter.
+// It returns the referenced table aliases, but does not add the registered joins. Use ApplyQuery to filter
+// and sort with automatic joins.
+func ApplySort(b sq.SelectBuilder, sort []SortField, opts ...Option) (sq.SelectBuilder, []string, error) {
+	ctx := newApplyContext(FromDefaultOptions(opts...))
+	orderBys, err := ctx.applySort(sort)
+	if err != nil {
+		return b, nil, err
+	}
+	return b.OrderBy(orderBys...), ctx.tableAliasList(), nil
+}
+
+// ApplyQuery applies the filter and the sort fields and adds the registered joins required by both.
+func ApplyQuery(b sq.SelectBuilder, condition filter.Condition, sort []SortField, opts ...Option) (sq.SelectBuilder, []string, error) {
+	sqlizer, ctx, err := buildFilter(condition, FromDefaultOptions(opts...))
+	if err != nil {
+		return b, nil, err
+	}
+	orderBys, err := ctx.applySort(sort)
+	if err != nil {
+		return b, nil, err
+	}
+	b, err = ctx.applyJoins(b)
+	if err != nil {
+		return b, nil, err
+	}
+	if sqlizer != nil {
+		b = b.Where(sqlizer)
+	}
+	return b.OrderBy(orderBys...), ctx.tableAliasList(), nil
+}
+
+func (ctx *ApplyContext) applySort(sort []SortField) ([]string, error) {
+	var orderBys []string
+	for _, s := range sort {
+		column, err := ctx.MapField(s.Field)
+		if err != nil {
+			return nil, err
+		}
+		if alias, ok := tableAlias(column); ok {
+			if j, ok := ctx.join(alias); ok && j.ToMany {
+				return nil, fmt.Errorf("cannot sort by field %s of to-many join %s", s.Field, alias)
+			}
+		}
+		direction := s.Direction
+		if direction == "" {
+			direction = Asc
+		}
+		switch direction {
+		case Asc, Desc:
+		default:
+			return nil, fmt.Errorf("invalid sort direction for field %s: %s", s.Field, s.Direction)
+		}
+		switch s.Nulls {
+		case NullsDefault, NullsFirst, NullsLast:
+		default:
+			return nil, fmt.Errorf("invalid nulls order for field %s: %s", s.Field, s.Nulls)
+		}
+		orderBys = append(orderBys, ctx.Dialect().OrderBy(column, direction, s.Nulls)...)
+	}
+	return orderBys, nil
+}
+
+// orderByNulls renders NULLS FIRST and NULLS LAST natively.
+func orderByNulls(column string, direction SortDirection, nulls NullsOrder) []string {
+	if nulls == NullsDefault {
+		return []string{fmt.Sprintf("%s %s", column, direction)}
+	}
+	return []string{fmt.Sprintf("%s %s %s", column, direction, nulls)}
+}
+
+// orderByNullsEmulated emulates NULLS FIRST and NULLS LAST with an additional sort expression.
+func orderByNullsEmulated(column string, direction SortDirection, nulls NullsOrder) []string {
+	orderBy := fmt.Sprintf("%s %s", column, direction)
+	switch nulls {
+	case NullsFirst:
+		return []string{fmt.Sprintf("CASE WHEN %s IS NULL THEN 0 ELSE 1 END", column), orderBy}
+	case NullsLast:
+		return []string{fmt.Sprintf("CASE WHEN %s IS NULL THEN 1 ELSE 0 END", column), orderBy}
+	}
+	return []string{orderBy}
+}
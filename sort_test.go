@@ -0,0 +1,140 @@
+package filtersquirrel
+
+import (
+	"fmt"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/require"
+	"github.com/xafelium/filter"
+	"testing"
+)
+
+func TestParseSort(t *testing.T) {
+	sort, err := ParseSort("-created_at, name,+id,")
+	require.NoError(t, err)
+	require.Equal(t, []SortField{
+		{Field: "created_at", Direction: Desc},
+		{Field: "name", Direction: Asc},
+		{Field: "id", Direction: Asc},
+	}, sort)
+
+	_, err = ParseSort("name,-")
+	require.ErrorContains(t, err, "invalid sort field: -")
+}
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestApplySort(t *testing.T) {
+	mapperFunc := WithMapperFunc(func(fieldName string) (string, error) {
+		switch fieldName {
+		case "name", "created_at":
+			return "u." + fieldName, nil
+		case "city":
+			return "a.city", nil
+		case "tag":
+			return "t.name", nil
+		}
+		return "", fmt.Errorf("unknown field: %s", fieldName)
+	})
+
+	tests := []struct {
+		name                 string
+		sort                 []SortField
+		opts                 []Option
+		expectedSql          string
+		expectedTableAliases []string
+		errContains          string
+	}{
+		{
+			name:        "no sort",
+			expectedSql: "SELECT * FROM users u",
+		},
+		{
+			name: "multiple fields",
+			sort: []SortField{
+				{Field: "created_at", Direction: Desc},
+				{Field: "city"},
+			},
+			expectedSql:          "SELECT * FROM users u ORDER BY u.created_at DESC, a.city ASC",
+			expectedTableAliases: []string{"u", "a"},
+		},
+		{
+			name:                 "nulls last",
+			sort:                 []SortField{{Field: "name", Direction: Asc, Nulls: NullsLast}},
+			expectedSql:          "SELECT * FROM users u ORDER BY u.name ASC NULLS LAST",
+			expectedTableAliases: []string{"u"},
+		},
+		{
+			name:                 "mysql nulls first",
+			sort:                 []SortField{{Field: "name", Direction: Desc, Nulls: NullsFirst}},
+			opts:                 []Option{WithDialect(MySQL)},
+			expectedSql:          "SELECT * FROM users u ORDER BY CASE WHEN u.name IS NULL THEN 0 ELSE 1 END, u.name DESC",
+			expectedTableAliases: []string{"u"},
+		},
+		{
+			name:                 "sqlserver nulls last",
+			sort:                 []SortField{{Field: "name", Nulls: NullsLast}},
+			opts:                 []Option{WithDialect(SQLServer)},
+			expectedSql:          "SELECT * FROM users u ORDER BY CASE WHEN u.name IS NULL THEN 1 ELSE 0 END, u.name ASC",
+			expectedTableAliases: []string{"u"},
+		},
+		{
+			name:        "unmapped field",
+			sort:        []SortField{{Field: "password"}},
+			errContains: "unknown field: password",
+		},
+		{
+			name:        "invalid direction",
+			sort:        []SortField{{Field: "name", Direction: "UP"}},
+			errContains: "invalid sort direction for field name: UP",
+		},
+		{
+			name:        "invalid nulls order",
+			sort:        []SortField{{Field: "name", Nulls: "NULLS NEVER"}},
+			errContains: "invalid nulls order for field name: NULLS NEVER",
+		},
+		{
+			name:        "to-many join",
+			sort:        []SortField{{Field: "tag"}},
+			opts:        []Option{WithJoin(Join{Alias: "t", Table: "tags t", On: "t.user_id = u.id", ToMany: true})},
+			errContains: "cannot sort by field tag of to-many join t",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := append([]Option{mapperFunc}, test.opts...)
+			builder, tableAliases, err := ApplySort(sq.Select("*").From("users u"), test.sort, opts...)
+
+			if test.errContains != "" {
+				require.ErrorContains(t, err, test.errContains)
+				return
+			}
+			require.NoError(t, err)
+			sql, _, err := builder.ToSql()
+			require.NoError(t, err)
+			require.Equal(t, test.expectedSql, sql)
+			require.Equal(t, test.expectedTableAliases, tableAliases)
+		})
+	}
+}
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestApplyQuery(t *testing.T) {
+	builder, tableAliases, err := ApplyQuery(
+		sq.Select("u.*").From("users u").PlaceholderFormat(sq.Dollar),
+		filter.Where(filter.Equals("name", "John")),
+		[]SortField{{Field: "city", Direction: Desc}},
+		WithMapperFunc(func(fieldName string) (string, error) {
+			if fieldName == "city" {
+				return "a.city", nil
+			}
+			return "u." + fieldName, nil
+		}),
+		WithJoin(Join{Alias: "a", Type: LeftJoin, Table: "addresses a", On: "a.id = u.address_id"}),
+	)
+	require.NoError(t, err)
+	sql, args, err := builder.ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT u.* FROM users u LEFT JOIN addresses a ON a.id = u.address_id WHERE u.name = $1 ORDER BY a.city DESC", sql)
+	require.Equal(t, []any{"John"}, args)
+	require.Equal(t, []string{"u", "a"}, tableAliases)
+}
@@ -0,0 +1,212 @@
+package filtersquirrel
+
+import (
+	"database/sql/driver"
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Struct tags read by NewStructMapper.
+const (
+	FilterTag = "filter"
+	ColumnTag = "db"
+	AliasTag  = "alias"
+)
+
+// StructMapper maps the filter field names declared in the tags of a struct to database columns.
+//
+//	type User struct {
+//		ID      int     `filter:"id" db:"u.id"`
+//		Email   string  `filter:"email" db:"email" alias:"u"`
+//		Address Address `filter:"address" alias:"a"`
+//	}
+//
+// The filter tag declares the field name, fields without it are not filterable. Its options declare the FieldType
+// of the field, e.g. `filter:"price,type=decimal"` or `filter:"status,enum=active|archived"`, which otherwise
+// is derived from the Go type. The db tag declares the column
+// and defaults to the field name. The alias tag qualifies unqualified columns with a table alias and is inherited
+// by the fields of nested structs. Nested structs prefix their field names with the name of the struct field,
+// e.g. "address.city", while the fields of embedded structs are promoted. Recursive structs are not descended into again.
+type StructMapper struct {
+	columns map[string]string
+	// fields map the columns back to the first declared field name, see MapColumn.
+	fields map[string]string
+	// indexes are the reflect index sequences of the struct fields.
+	indexes map[string][]int
+	types   map[string]FieldType
+}
+
+// NewStructMapper creates a StructMapper from the tags of the struct v or a pointer to it.
+func NewStructMapper(v any) (*StructMapper, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected struct but got %T", v)
+	}
+	m := &StructMapper{
+		columns: make(map[string]string),
+		fields:  make(map[string]string),
+		indexes: make(map[string][]int),
+		types:   make(map[string]FieldType),
+	}
+	if err := m.addStruct(t, nil, "", "", map[reflect.Type]bool{}); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *StructMapper) addStruct(t reflect.Type, index []int, prefix string, alias string, visited map[reflect.Type]bool) error {
+	if visited[t] {
+		return nil
+	}
+	visited[t] = true
+	defer delete(visited, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, tagOptions, _ := strings.Cut(f.Tag.Get(FilterTag), ",")
+		if name == "-" || (!f.IsExported() && !f.Anonymous) {
+			continue
+		}
+		fieldAlias := alias
+		if a, ok := f.Tag.Lookup(AliasTag); ok {
+			fieldAlias = a
+		}
+		column, hasColumn := f.Tag.Lookup(ColumnTag)
+		fieldIndex := append(append([]int{}, index...), i)
+
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct && !hasColumn && !isValueStruct(ft) {
+			switch {
+			case name != "":
+				if err := m.addStruct(ft, fieldIndex, prefix+name+".", fieldAlias, visited); err != nil {
+					return err
+				}
+				continue
+			case f.Anonymous:
+				if err := m.addStruct(ft, fieldIndex, prefix, fieldAlias, visited); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if name == "" {
+			continue
+		}
+		if column == "" {
+			column = name
+		}
+		if fieldAlias != "" && !strings.Contains(column, ".") {
+			column = fieldAlias + "." + column
+		}
+		name = prefix + name
+		if _, exists := m.columns[name]; exists {
+			return fmt.Errorf("duplicate filter field name: %s", name)
+		}
+		m.columns[name] = column
+		if _, exists := m.fields[column]; !exists {
+			m.fields[column] = name
+		}
+		m.indexes[name] = fieldIndex
+		fieldType, ok, err := tagFieldType(tagOptions, f.Type)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", name, err)
+		}
+		if ok {
+			m.types[name] = fieldType
+		}
+	}
+	return nil
+}
+
+// tagFieldType returns the FieldType declared by the options of the filter tag or derived from the Go type.
+func tagFieldType(tagOptions string, t reflect.Type) (FieldType, bool, error) {
+	for _, option := range strings.Split(tagOptions, ",") {
+		key, value, _ := strings.Cut(option, "=")
+		switch key {
+		case "":
+		case "type":
+			fieldType, ok := fieldTypeNames[value]
+			if !ok {
+				return FieldType{}, false, fmt.Errorf("unknown field type: %s", value)
+			}
+			return fieldType, true, nil
+		case "enum":
+			return EnumType(strings.Split(value, "|")...), true, nil
+		default:
+			return FieldType{}, false, fmt.Errorf("unknown filter tag option: %s", key)
+		}
+	}
+	fieldType, ok := fieldTypeOf(t)
+	return fieldType, ok, nil
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	valuerType        = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// isValueStruct reports whether the struct is stored in a single column, like time.Time, rather than being nested.
+func isValueStruct(t reflect.Type) bool {
+	pt := reflect.PointerTo(t)
+	return t == timeType ||
+		t.Implements(valuerType) || pt.Implements(valuerType) ||
+		t.Implements(textMarshalerType) || pt.Implements(textMarshalerType)
+}
+
+// Map is a FieldMapperFunc which rejects fields not declared in the struct.
+func (m *StructMapper) Map(fieldName string) (string, error) {
+	column, ok := m.columns[fieldName]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownField, fieldName)
+	}
+	return column, nil
+}
+
+// MapColumn is a ColumnMapperFunc mapping a column back to its field name. If several fields share the column,
+// it returns the one declared first.
+func (m *StructMapper) MapColumn(column string) (string, error) {
+	field, ok := m.fields[column]
+	if !ok {
+		return "", fmt.Errorf("unknown column: %s", column)
+	}
+	return field, nil
+}
+
+// Fields returns the sorted names of the filterable fields.
+func (m *StructMapper) Fields() []string {
+	fields := make([]string, 0, len(m.columns))
+	for field := range m.columns {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// FieldTypes returns the FieldType of the filterable fields, see WithFieldTypes.
+func (m *StructMapper) FieldTypes() map[string]FieldType {
+	types := make(map[string]FieldType, len(m.types))
+	for field, t := range m.types {
+		types[field] = t
+	}
+	return types
+}
+
+// MapperFuncFromStruct creates a FieldMapperFunc from the tags of the struct v. See StructMapper.
+func MapperFuncFromStruct(v any) (FieldMapperFunc, error) {
+	m, err := NewStructMapper(v)
+	if err != nil {
+		return nil, err
+	}
+	return m.Map, nil
+}
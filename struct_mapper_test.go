@@ -0,0 +1,125 @@
+package filtersquirrel
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/require"
+	"github.com/xafelium/filter"
+	"testing"
+	"time"
+)
+
+type testAuditable struct {
+	CreatedAt time.Time `filter:"created_at" db:"u.created_at"`
+}
+
+type testAddress struct {
+	City    string `filter:"city"`
+	ZipCode string `filter:"zip" db:"zip_code"`
+	Country struct {
+		Code string `filter:"code" db:"c.iso_code"`
+	} `filter:"country"`
+}
+
+type testUser struct {
+	testAuditable
+	UpdatedAt *time.Time   `filter:"updated_at" alias:"u"`
+	ID        int          `filter:"id" db:"u.id"`
+	Email     string       `filter:"email" db:"email" alias:"u"`
+	Password  string       `db:"u.password"`
+	Internal  string       `filter:"-"`
+	Address   *testAddress `filter:"address" alias:"a"`
+	Manager   *testUser    `filter:"manager" alias:"m"`
+	secret    string
+}
+
+func TestNewStructMapper(t *testing.T) {
+	m, err := NewStructMapper(&testUser{})
+	require.NoError(t, err)
+
+	require.Equal(t, []string{
+		"address.city",
+		"address.country.code",
+		"address.zip",
+		"created_at",
+		"email",
+		"id",
+		"updated_at",
+	}, m.Fields())
+
+	tests := map[string]string{
+		"id":                   "u.id",
+		"email":                "u.email",
+		"created_at":           "u.created_at",
+		"updated_at":           "u.updated_at",
+		"address.city":         "a.city",
+		"address.zip":          "a.zip_code",
+		"address.country.code": "c.iso_code",
+	}
+	for field, column := range tests {
+		actual, err := m.Map(field)
+		require.NoError(t, err)
+		require.Equal(t, column, actual)
+
+		actual, err = m.MapColumn(column)
+		require.NoError(t, err)
+		require.Equal(t, field, actual)
+	}
+
+	_, err = m.MapColumn("u.password")
+	require.ErrorContains(t, err, "unknown column: u.password")
+
+	for _, field := range []string{"password", "Password", "Internal", "secret", "manager.id", "address"} {
+		_, err := m.Map(field)
+		require.ErrorContains(t, err, "unknown field: "+field)
+	}
+}
+
+func TestStructMapperSharedColumn(t *testing.T) {
+	m, err := NewStructMapper(struct {
+		Name     string `filter:"name" db:"name"`
+		FullName string `filter:"full_name" db:"name"`
+		Nick     string `filter:"nick" db:"name"`
+	}{})
+	require.NoError(t, err)
+
+	for i := 0; i < 20; i++ {
+		field, err := m.MapColumn("name")
+		require.NoError(t, err)
+		require.Equal(t, "name", field)
+	}
+}
+
+func TestNewStructMapperErrors(t *testing.T) {
+	_, err := NewStructMapper(42)
+	require.ErrorContains(t, err, "expected struct but got int")
+
+	_, err = NewStructMapper(struct {
+		A string `filter:"a"`
+		B string `filter:"a"`
+	}{})
+	require.ErrorContains(t, err, "duplicate filter field name: a")
+}
+
+//goland:noinspection SqlNoDataSourceInspection,SqlResolve
+func TestApplyFilterWithStructMapper(t *testing.T) {
+	mapperFunc, err := MapperFuncFromStruct(testUser{})
+	require.NoError(t, err)
+
+	builder, tableAliases, err := ApplyFilter(
+		sq.Select("*").From("users u").Join("addresses a ON a.id = u.address_id"),
+		filter.Where(filter.And(
+			filter.Equals("email", "x@y.z"),
+			filter.Equals("address.city", "Berlin"),
+		)),
+		WithMapperFunc(mapperFunc),
+	)
+	require.NoError(t, err)
+	sql, args, err := builder.ToSql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT * FROM users u JOIN addresses a ON a.id = u.address_id WHERE (u.email = ? AND a.city = ?)", sql)
+	require.Equal(t, []any{"x@y.z", "Berlin"}, args)
+	require.Equal(t, []string{"u", "a"}, tableAliases)
+
+	_, _, err = ApplyFilter(sq.Select("*").From("users u"), filter.Equals("password", "x"), WithMapperFunc(mapperFunc))
+	require.ErrorContains(t, err, "unknown field: password")
+}
@@ -0,0 +1,96 @@
+package filtersquirrel
+
+import (
+	"fmt"
+	"github.com/xafelium/filter"
+	"strings"
+)
+
+// Violation describes a condition which is not allowed for a field or compares it with an invalid value.
+type Violation struct {
+	// Path is the position of the condition in the tree, e.g. "where.and[2].or[0]".
+	Path          string
+	Field         string
+	ConditionType string
+	// Message describes the invalid value. It is empty if the condition is not allowed.
+	Message string
+}
+
+func (v Violation) String() string {
+	if v.Message != "" {
+		return fmt.Sprintf("%s: invalid value for field %s: %s", v.Path, v.Field, v.Message)
+	}
+	return fmt.Sprintf("%s: %s is not allowed for field %s", v.Path, v.ConditionType, v.Field)
+}
+
+// ValidationError is returned if a filter contains conditions not allowed by WithAllowedConditions
+// or values which cannot be coerced to the FieldType of their field.
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.String()
+	}
+	return fmt.Sprintf("invalid filter: %s", strings.Join(messages, "; "))
+}
+
+// Is reports whether the target is ErrInvalidValue and a violation describes an invalid value.
+func (e *ValidationError) Is(target error) bool {
+	if target != ErrInvalidValue {
+		return false
+	}
+	for _, v := range e.Violations {
+		if v.Message != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the condition against the allowed conditions and the field types per field without building
+// any SQL. ApplyFilter validates the condition automatically if allowed conditions or field types are configured.
+func Validate(condition filter.Condition, opts ...Option) error {
+	return validate(condition, FromDefaultOptions(opts...))
+}
+
+func validate(condition filter.Condition, options *Options) error {
+	if len(options.AllowedConditions) == 0 && len(options.FieldTypes) == 0 {
+		return nil
+	}
+	var violations []Violation
+	_ = walkCondition(condition, "", func(c filter.Condition, path string) error {
+		field, ok := conditionField(c)
+		if !ok {
+			return nil
+		}
+		allowed, restricted := fieldOption(options, options.AllowedConditions, field)
+		if restricted && !allowed[c.Type()] {
+			violations = append(violations, Violation{
+				Path:          displayPath(c, path),
+				Field:         field,
+				ConditionType: c.Type(),
+			})
+			return nil
+		}
+		if !coercedConditionTypes[c.Type()] {
+			return nil
+		}
+		value, _ := conditionValue(c)
+		if _, err := coerceFieldValue(options, field, value); err != nil {
+			violations = append(violations, Violation{
+				Path:          displayPath(c, path),
+				Field:         field,
+				ConditionType: c.Type(),
+				Message:       err.Error(),
+			})
+		}
+		return nil
+	})
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package filtersquirrel
+
+import (
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/require"
+	"github.com/xafelium/filter"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	opts := []Option{
+		WithAllowedConditions("created_at",
+			filter.GreaterThanConditionType,
+			filter.LowerThanConditionType,
+		),
+		WithAllowedConditions("tags", filter.ArrayContainsConditionType),
+		WithJSONField("attributes", "metrics"),
+		WithAllowedConditions("attributes", filter.EqualsConditionType),
+		WithAllowedConditions("attributes.color", filter.EqualsConditionType, filter.RegexConditionType),
+		WithFieldType(IntType, "metrics"),
+	}
+
+	tests := []struct {
+		name               string
+		filter             filter.Condition
+		expectedViolations []Violation
+	}{
+		{
+			name:   "nil condition",
+			filter: nil,
+		},
+		{
+			name: "allowed conditions",
+			filter: filter.Where(filter.And(
+				filter.GreaterThan("created_at", 1),
+				filter.ArrayContains("tags", "a"),
+				filter.Regex("name", "^a"),
+			)),
+		},
+		{
+			name:   "root condition",
+			filter: filter.Regex("created_at", "2024"),
+			expectedViolations: []Violation{
+				{Path: "regex", Field: "created_at", ConditionType: filter.RegexConditionType},
+			},
+		},
+		{
+			name: "nested conditions",
+			filter: filter.Where(filter.And(
+				filter.GreaterThan("created_at", 1),
+				filter.Not(filter.Equals("tags", "a")),
+				filter.Group(filter.Or(
+					filter.Regex("created_at", "2024"),
+					filter.LowerThan("created_at", 2),
+				)),
+			)),
+			expectedViolations: []Violation{
+				{Path: "where.and[1].not", Field: "tags", ConditionType: filter.EqualsConditionType},
+				{Path: "where.and[2].group.or[0]", Field: "created_at", ConditionType: filter.RegexConditionType},
+			},
+		},
+		{
+			name: "paths into JSON fields",
+			filter: filter.Where(filter.And(
+				filter.Equals("attributes.size", "XL"),
+				filter.Regex("attributes.color", "^bl"),
+				filter.Regex("attributes.size", "^X"),
+				filter.GreaterThan("metrics.visits", "many"),
+			)),
+			expectedViolations: []Violation{
+				{Path: "where.and[2]", Field: "attributes.size", ConditionType: filter.RegexConditionType},
+				{
+					Path:          "where.and[3]",
+					Field:         "metrics.visits",
+					ConditionType: filter.GreaterThanConditionType,
+					Message:       `"many" is no integer`,
+				},
+			},
+		},
+		{
+			name:   "conditions of this package",
+			filter: filter.Where(StartsWith("tags", "a")),
+			expectedViolations: []Violation{
+				{Path: "where", Field: "tags", ConditionType: StartsWithConditionType},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := Validate(test.filter, opts...)
+
+			if test.expectedViolations == nil {
+				require.NoError(t, err)
+				return
+			}
+			var validationErr *ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			require.Equal(t, test.expectedViolations, validationErr.Violations)
+		})
+	}
+}
+
+func TestApplyFilterValidatesAllowedConditions(t *testing.T) {
+	_, _, err := ApplyFilter(
+		sq.Select("*").From("users"),
+		filter.Where(filter.Or(filter.Regex("created_at", "2024"), filter.Equals("id", 1))),
+		WithAllowedConditions("created_at", filter.GreaterThanConditionType),
+		WithMapperFunc(func(fieldName string) (string, error) {
+			panic("mapper must not be called for invalid filters")
+		}),
+	)
+	require.EqualError(t, err, "invalid filter: where.or[0]: RegexCondition is not allowed for field created_at")
+}
@@ -0,0 +1,114 @@
+package filtersquirrel
+
+import (
+	"fmt"
+	"github.com/xafelium/filter"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// childCondition is a nested condition together with the path segment leading to it.
+type childCondition struct {
+	condition filter.Condition
+	segment   string
+}
+
+// conditionChildren returns the nested conditions of the logical conditions.
+func conditionChildren(condition filter.Condition) []childCondition {
+	switch c := condition.(type) {
+	case *filter.WhereCondition:
+		return []childCondition{{condition: c.Condition, segment: conditionName(c)}}
+	case *filter.GroupCondition:
+		return []childCondition{{condition: c.Condition, segment: conditionName(c)}}
+	case *filter.NotCondition:
+		return []childCondition{{condition: c.Condition, segment: conditionName(c)}}
+	case *filter.AndCondition:
+		return indexedChildren(c, c.Conditions)
+	case *filter.OrCondition:
+		return indexedChildren(c, c.Conditions)
+	}
+	return nil
+}
+
+func indexedChildren(parent filter.Condition, conditions []filter.Condition) []childCondition {
+	children := make([]childCondition, len(conditions))
+	for i, c := range conditions {
+		children[i] = childCondition{condition: c, segment: fmt.Sprintf("%s[%d]", conditionName(parent), i)}
+	}
+	return children
+}
+
+// conditionName returns the short name of a condition used in paths, e.g. "and" for an AndCondition.
+func conditionName(condition filter.Condition) string {
+	name := strings.TrimSuffix(condition.Type(), "Condition")
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToLower(r)) + name[size:]
+}
+
+// conditionField returns the field name of a comparison condition. Conditions of custom types are supported
+// if they have a string field named Field like the conditions of the filter package.
+func conditionField(condition filter.Condition) (string, bool) {
+	f, ok := conditionStructField(condition, "Field")
+	if !ok || f.Kind() != reflect.String {
+		return "", false
+	}
+	return f.String(), true
+}
+
+// conditionValue returns the value of a comparison condition, i.e. its field named Value.
+func conditionValue(condition filter.Condition) (any, bool) {
+	f, ok := conditionStructField(condition, "Value")
+	if !ok || !f.CanInterface() {
+		return nil, false
+	}
+	return f.Interface(), true
+}
+
+func conditionStructField(condition filter.Condition, name string) (reflect.Value, bool) {
+	v := reflect.ValueOf(condition)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	f := v.FieldByName(name)
+	return f, f.IsValid()
+}
+
+// walkCondition calls visit for the condition and all nested conditions in depth-first order.
+// The path describes the position of the condition in the tree, e.g. "where.and[2].or[0]".
+func walkCondition(condition filter.Condition, path string, visit func(c filter.Condition, path string) error) error {
+	if condition == nil {
+		return nil
+	}
+	if err := visit(condition, path); err != nil {
+		return err
+	}
+	for _, child := range conditionChildren(condition) {
+		if err := walkCondition(child.condition, joinPath(path, child.segment), visit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func joinPath(path string, segment string) string {
+	if path == "" {
+		return segment
+	}
+	return path + "." + segment
+}
+
+// displayPath returns the path of the condition for messages, which is its own name for the root condition.
+func displayPath(condition filter.Condition, path string) string {
+	if path == "" {
+		return conditionName(condition)
+	}
+	return path
+}